@@ -85,7 +85,7 @@ func static_dygraph_combined_js() (*asset, error) {
 	return a, nil
 }
 
-var _static_index_html = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xcc\x58\x79\x6f\xdb\x3a\x12\xff\xdf\x9f\x82\x51\x11\x54\x6e\x1c\x29\x49\x77\x8b\xc2\x17\xd0\x1c\x3d\x16\xdd\x74\xb1\xf5\xee\x3f\x81\x51\xd0\x12\x6d\xb3\x91\x45\x81\xa2\x93\xf8\xa5\xf9\xee\x6f\x86\xa4\x24\x4a\xb2\xfb\xd2\x13\x2f\x40\x6c\x99\x73\x70\xe6\x37\xc3\x99\xa1\x86\x4b\xb5\x4a\xc6\x9d\xe1\x92\xd1\x78\xdc\x21\x64\xa8\xb8\x4a\xd8\x78\x25\xa4\xe2\x2b\x9e\xaa\x61\x68\x16\x90\x94\x47\x92\x67\x8a\xe4\x32\x1a\x79\x89\x88\x69\xbe\x0c\x80\x25\xf8\x9c\x7b\xe3\x61\x68\x88\x2d\xbe\x78\xb3\x90\x34\x5b\x1e\x46\x62\x35\xe3\x29\x8b\x1b\xdc\xc3\xd0\x6c\x3c\x9c\x89\x78\x03\x5f\x31\xbf\x21\x51\x42\xf3\x7c\xe4\xad\x28\x4f\x3d\xad\x6f\x79\xec\xda\x03\xbf\x3a\xb8\xea\xb0\x46\x4b\x2a\xd5\x99\x48\x15\x88\x30\xa9\x85\xb6\x30\x78\xa1\xd6\x16\xc2\x7a\x4b\x43\x22\x16\x1f\x97\xe2\xb6\x10\x4d\xe8\x8c\x25\x0d\xda\x7b\x5c\x43\xdb\x35\xd1\x32\x66\x92\x35\xd8\xd0\x0c\x96\xaa\x53\x36\x17\x92\x21\x3b\xb0\xfc\x15\xf3\x2b\xe5\x30\x6e\x37\x30\x93\x62\x21\x59\x0e\xe0\x05\x41\xb0\xc3\x07\x9e\x5e\xe7\x85\x07\xcb\x93\xb1\xfe\x0d\x78\x9d\xd8\x25\x4a\x96\x92\xcd\x47\x5e\x58\xe9\x2a\x9e\x86\x21\xb5\x4c\x33\x19\x36\xd9\xc5\x5a\x9d\x73\x19\x7a\x63\xf3\xf0\x55\x5e\x1d\xec\x73\xaa\xa8\x37\x2e\x1f\xad\x80\x35\xba\xf8\x2a\x32\xe0\x86\x4a\x82\xa1\xbe\x48\xc8\x88\xc4\x22\x5a\xaf\x00\x90\x60\xc1\xd4\x45\xc2\xf0\x31\x3f\xdd\x9c\xa1\x7f\x97\x74\xc5\x7c\x93\x14\xdd\xab\xa3\xe9\x40\x0b\x16\xf6\x3f\x4e\xb8\xf4\xbb\x52\xa0\x13\xe3\x71\xd2\x26\x87\x2a\x51\x37\x2d\x1e\xa7\xa1\x96\x48\x2d\x45\xb5\xc4\xf9\x26\x85\xf5\x94\xdb\xa5\xf8\xd5\x23\xfd\x6c\xa5\xa6\x51\xd8\x09\x43\x72\xf8\x8d\x7f\x1d\x63\x06\xcd\xd5\x7f\x2c\xf4\x60\xc0\xfd\x03\x28\x9b\xaf\xd3\x48\x71\x91\x92\x75\x16\x53\xc5\x0a\xb2\xdf\x25\xf7\x90\x2a\x73\xa6\xa2\xa5\xef\x05\x55\xa6\x76\x75\x9e\x05\x6a\xc9\x52\xbf\x90\xf5\x81\x92\x89\x34\x67\x46\x08\xff\xf8\x9c\x94\xab\x41\xae\xa8\x5a\xe7\x64\x6f\x44\x4e\x8e\x8e\x2a\x1e\x42\x24\x53\x6b\x99\x92\x08\xb8\x44\xc2\x02\x70\xd8\xf7\xf4\x96\xa4\xdc\x90\xa4\x42\xa1\x98\xd7\x23\xe5\x2e\x03\xab\xe0\xa1\xd3\x29\xf4\xd8\x9d\x3e\xe7\x60\x4d\xb7\x61\x1d\xf8\x45\xdd\x5d\x1b\x30\x20\x79\xd0\x29\xa9\x75\x1c\x4e\xa9\xcc\x8d\x02\x87\x05\x9d\xc3\xb5\xe0\x62\xc5\xd5\xb9\x48\x99\xab\x9d\xd8\x43\x14\x44\x45\x24\xc9\xc1\x88\x78\xe5\x09\x41\x7e\xcf\x51\x56\xec\x78\xce\x23\xe5\x97\xae\x81\x73\x84\x25\x39\xab\x29\xce\x99\x9a\xf0\x15\x83\xd3\xef\xd7\xad\xec\x91\xe3\x7f\x02\xb2\x8e\x70\x81\x90\x5d\x7b\xb0\x61\x8b\x28\xc6\xb3\x44\x86\x49\x09\xa6\x6f\xc3\x1f\x28\x42\x02\xe6\xc8\x31\xd0\x6a\x1e\x3a\xcd\x0c\xd9\x9d\x3d\x15\x6a\xda\x7e\x5d\x20\x22\x85\x60\x5f\x4d\xd1\x20\x38\x1e\xc4\xc7\xd5\x98\x4b\xc2\x53\x1d\x82\xe0\x35\x4f\xd8\x47\xfe\x07\xcb\x0b\x34\x4b\xae\x79\x8a\x28\xb6\xf8\xae\x40\x7a\x5a\x41\x8f\x9c\x39\xac\xdb\x90\x36\xf8\xae\xb4\x92\xe9\xc0\x61\x8e\xd6\x52\x02\xf3\x27\x3c\x80\x7e\x29\x52\x61\x8a\x72\x3d\xb3\xf9\xb4\x47\x2a\x78\xd1\x95\x20\x5b\xe7\x4b\x7f\x46\xe5\x64\x95\x25\xfe\x3d\x70\xf6\x81\x82\x02\x4e\xbc\x1a\x7f\x5a\x53\xdf\x7c\x7d\x85\xed\x96\xc7\x6a\xd9\x07\xb3\x94\x78\x07\x27\x7f\xc1\xa4\x0f\xc1\x25\xcf\x88\x8f\x06\x87\xe8\x62\xb7\xfb\xd0\x2d\x22\xdb\x31\xff\x55\x01\x0e\x78\x0a\xbd\xf7\xed\xe4\xdf\xef\xc1\x39\x6d\xeb\x67\xc1\x53\xdf\xf3\x74\x10\x75\x21\xb0\x76\x6b\xe7\x15\x83\x27\x88\x9d\x0f\x4a\x9e\xba\x5d\x0c\x98\xbc\xf1\xd3\x03\xbd\x4b\x93\x80\x69\x0d\x9d\x72\x7f\xa4\x23\xb8\x3f\x0e\xf1\xd1\x84\x69\x7f\x6c\x1a\xcb\x0e\xc9\xff\xd3\xc4\x23\xb9\xda\x24\x0c\x06\x0b\x9e\x1e\x5a\x6f\x51\x5e\x3f\x82\x7c\x76\x37\xf0\x5c\x25\x4f\xed\x73\xf7\x47\x6b\x1f\x1e\xb1\x1d\x75\xcf\x9c\xbe\x7a\xcd\xb3\xed\x96\xc1\x21\x0f\x62\xa0\xff\xb6\xda\xd7\xdc\xf8\x17\xd5\x40\x0b\x47\xa3\xfe\x6d\xaf\x5e\x68\x46\xb3\x72\x19\xe4\xde\x14\xd3\x85\xdf\xfd\x15\x45\xe7\xfb\x23\x5e\x8e\x3d\x36\xe4\xb5\xb5\xcb\xf5\x0a\x96\x8f\xda\x89\xe0\xb8\xa3\xe1\x72\x25\x0e\x0e\xb4\xf7\x65\x82\x54\x33\xd6\xef\xca\x8c\xca\xa7\x9f\x9f\x12\x2e\x5e\x5b\x7b\xe2\x19\xce\x5d\xad\x66\xd8\x6a\x4a\x25\x82\x3d\x34\xb0\x2a\x9b\x7f\x97\xb4\x00\xf7\xd2\x75\x92\x0c\x08\xa8\x78\x0f\xf6\x4a\x28\x3c\x49\x42\x66\x8c\x50\x72\x6e\x2e\x49\xd0\x6b\x20\x3a\x69\xc4\x82\x4e\x35\x9c\xb2\xf8\x4d\x23\xa3\x9a\xb9\x63\x00\x2a\x71\x34\xe0\x62\xcc\x9b\xf2\xc1\x7f\xd9\x0d\x19\x8d\x2a\xc8\x71\xa1\x88\x85\x09\xbf\x2d\xeb\x5b\x76\x2e\x85\x74\x10\x4c\x6d\x83\x39\x56\x77\x57\x6f\x92\x7b\xd3\x00\x30\x04\x70\xfd\x4f\xc1\x35\xdb\xe4\x95\x3d\x81\x36\x4a\x47\x01\x8d\x32\x52\x41\xc2\xd2\x05\x94\xdd\xe1\x88\x1c\xd7\x2d\x18\x74\x6c\x2a\xe9\x46\x6d\xf6\xc6\xf6\x0d\x2b\x9f\x02\xe8\xcd\x17\x14\xc2\x57\x57\x0e\xdd\xb2\x08\xa7\x26\x5c\xa4\x4a\xf2\xaa\xa1\x37\xc4\x2c\x75\x9b\xd0\xa6\x4a\x4c\xdd\x98\x75\xb7\xbd\x4a\xd9\x2d\x39\xc7\x56\x55\xf1\x05\x93\xbc\xdb\x23\x19\xcc\x1b\x0c\x9a\xa5\x4b\x80\x46\xd3\x9d\x76\x3b\x55\xe6\x3d\x98\xac\x45\xd7\xf7\x16\x85\x7e\x9d\x0d\xa8\xd6\x04\xde\xb7\xd7\x90\x9e\xde\xb6\x57\x1a\x61\xb0\xea\xdb\xef\xa2\x79\xdb\xde\x75\x0c\x59\x5e\x2c\x2d\x19\x5f\x2c\x55\x1f\x33\xbf\x58\x8a\x12\x1e\x5d\x9f\xd1\x24\x99\xd1\xe8\xba\x4f\x44\xaa\xd3\xe4\x0c\x57\x4b\x29\x10\x4a\x50\xb0\xc5\xf6\xb6\xa0\xf4\x5c\x4f\xdc\xd1\x70\x11\x98\xe4\xfb\x90\x21\x84\xb9\x7f\x3f\x87\x21\xa6\x6f\xed\xaf\xdb\x6d\xa5\xf1\xf8\x94\x99\xeb\x9a\xe3\xb3\x1b\xb8\x68\xf4\xc8\x1d\x20\x0a\x23\x83\xb2\x81\xb3\xf7\x90\x89\x04\xc3\x78\xba\x98\x88\xc5\x22\x61\xbe\x39\x86\x4d\x3d\xa5\xbd\x2d\x5d\x50\xa8\xc4\xed\xbb\x18\x7e\xe7\x0c\xc3\x8e\xbd\xa5\x1a\x0f\xe3\x22\x0e\x18\xde\x3b\x27\x52\x46\x98\x7c\xf9\x62\xd5\x38\xf9\x7a\x84\xab\x7b\xcd\x13\xf2\x95\x24\xd6\xf3\xc9\xc8\x2a\x82\xbb\x54\x80\x0b\x03\x4b\xac\x52\x07\x58\x5a\x07\x16\x3f\xae\xf4\x24\x78\x65\xdc\x98\x16\xc7\xa8\x9d\xb3\x16\x2f\x5f\xcf\x79\x8e\xde\x0a\xfd\xef\x9f\x62\xea\xa1\x00\x4b\x95\x5c\x33\xb7\x12\xed\x08\xd6\xb6\x40\x82\xf4\x5e\x63\xe9\xc7\x4a\xab\x55\xf6\x2e\x9d\xeb\x14\x00\xfd\x73\x0a\x89\xba\xc5\xbc\x36\x36\x65\xad\x6c\x9a\x84\x41\x6e\x28\xde\x11\xe2\xfa\x7b\x00\x33\x06\x4f\xd8\x1d\xda\xa1\x23\x7f\x00\x73\x8c\x07\x9f\xff\xfa\xf8\xe1\x12\xba\xaf\x04\xe5\x7c\xbe\xf1\x6b\x01\xaa\xd4\x38\x5e\x58\x8c\x4b\xd2\xff\x24\x8e\xce\x30\x00\xd8\xdf\x21\x6a\x75\x4a\x0f\x0c\x6f\xaf\x2f\xed\x8e\x4d\xda\x87\xf9\x1c\x3a\xe6\xe9\x46\x31\x67\x96\xa8\xf4\x3e\x72\x90\xd8\x8d\xf4\xcf\x19\x34\xd0\x68\xc7\x59\x44\xee\x17\xcc\xa0\x5b\x5f\xb7\xd4\xe2\x86\x22\x78\x50\x2d\xcf\x60\x87\x2c\xbe\x51\x69\xcb\x1d\xb7\xe4\x7e\xee\x08\xe2\xbc\x3c\xd5\x17\x9a\x71\x27\xa8\xbf\xff\x24\xe6\xb7\x76\xb9\xea\x15\xfb\x5a\x38\xb0\x1e\x68\xe2\x8a\xca\x05\x4f\x91\x9a\xdd\x39\x1f\x27\xf0\x81\xcc\x81\x9b\xd9\xe6\x8e\x02\x5b\x1c\xce\xe9\x8a\x27\x9b\x3e\x59\x89\x54\xe4\x19\x8d\xd8\xa0\xa0\xe0\x1d\xb1\x4f\x5e\x64\xca\x95\x2e\xb1\xd2\x1a\x66\x42\xc6\x4c\x1e\x2a\x91\xc1\xb6\xb0\x17\x78\xcc\x63\xf2\xe4\xf9\xf3\xe7\xc6\x3c\xf7\x75\x05\x29\x7f\x19\x51\x38\x98\x0b\x29\xd6\x69\x7c\x18\x89\x44\xc0\xcd\xf7\x09\x9b\xb3\xba\x18\x09\xe0\xae\xa7\xb9\x33\x91\x73\x44\xb7\x0f\xd9\x01\xf7\x4c\x7e\xa3\x39\x1b\x8c\xcf\x34\x6b\xcc\x73\xb8\x8a\x82\x47\x3c\x4d\x00\xbf\xc3\x59\x22\xa2\xeb\x86\x53\x2f\xad\x53\x75\xf9\xc0\xde\x49\x0d\x9a\xd5\xb5\xf2\x1f\x47\x05\x84\x6d\x7e\x18\x10\x76\x1a\x68\x94\x14\xcd\xfc\x05\xea\xd8\xea\xf7\x4b\x4a\x51\x3b\x64\x82\x49\x80\x61\x68\x5f\x9f\x87\xe6\x6d\xfe\x9f\x01\x00\x00\xff\xff\x91\xb1\xf9\x1c\xd5\x17\x00\x00")
+var _static_index_html = []byte("\x1f\x8b\x08\x00\x00\x09\x6e\x88\x00\xff\xcd\x19\x6b\x6f\xdb\x38\xf2\xbb\x7f\x05\xab\xbd\x6e\xe5\x4d\x2c\x3f\x72\x09\xba\x7e\x01\x6d\x92\xee\xf6\xd0\x6b\x0f\x8d\xef\x70\x40\x36\x57\xd0\x12\x6d\xb3\xa1\x45\x83\xa2\x93\x78\xb3\xf9\xef\x37\x43\x52\x12\x25\xdb\xbd\xb4\xd7\x16\x1b\x20\xb6\x34\x9c\x19\xce\x7b\x86\xf4\x70\xa1\x97\x62\xdc\x18\x2e\x18\x4d\xc6\x0d\x42\x86\x9a\x6b\xc1\xc6\x4b\xa9\x34\x5f\xf2\x54\x0f\xdb\x16\x80\x4b\x59\xac\xf8\x4a\x93\x4c\xc5\xa3\x40\xc8\x84\x66\x8b\x08\x50\xa2\x8f\x59\x30\x1e\xb6\xed\xe2\x16\x5e\xb2\x99\x2b\xba\x5a\xb4\x62\xb9\x9c\xf2\x94\x25\x35\xec\x61\xdb\x6e\x3c\x9c\xca\x64\x03\x5f\x09\xbf\x21\xb1\xa0\x59\x36\x0a\x96\x94\xa7\x81\xe1\xb7\xe8\xfa\xf2\xc0\x5b\x03\xa1\x1e\x6a\xbc\xa0\x4a\x9f\xca\x54\x03\x09\x53\x86\x68\x07\x42\xd0\x36\xdc\xda\x00\xdf\xe2\x20\xe4\xfc\x62\x21\x6f\x73\x52\x41\xa7\x4c\xd4\xd6\xde\x20\x0c\x65\x37\x8b\x0e\x71\xa5\x58\x0d\x0d\xc5\x60\xa9\x7e\xc9\x66\x52\x31\x44\x07\x94\xff\x85\xfc\x42\x7b\x88\xbb\x05\x5c\x29\x39\x57\x2c\x03\xe3\x45\x51\xb4\x47\x07\x9e\x5e\x67\xb9\x06\x8b\xde\xd8\xbc\x83\xbd\x7a\x0e\x44\xc9\x42\xb1\xd9\x28\x68\x97\xbc\xf2\xa7\x61\x9b\x3a\xa4\xa9\x6a\xd7\xd1\xe5\x5a\x9f\x71\xd5\x0e\xc6\xf6\xe1\x93\xb8\xc6\xd9\x67\x54\xd3\x60\x5c\x3c\x3a\x02\x27\x74\xfe\x95\x47\xc0\x0d\x55\x04\x5d\x7d\x2e\xc8\x88\x24\x32\x5e\x2f\xc1\x20\xd1\x9c\xe9\x73\xc1\xf0\x31\x7b\xb9\x39\x45\xfd\xde\xd2\x25\x0b\x6d\x50\x34\x2f\x3b\x57\x03\x43\x98\xcb\xff\x38\xe2\x42\xef\x92\x81\x09\x8c\xc7\x51\xdb\x18\x2a\x49\xfd\xb0\x78\x1c\x87\x4a\x20\x6d\x31\xaa\x04\xce\x67\x31\xac\x86\xdc\x3e\xc6\x2f\xf4\x97\x30\x7d\x91\xab\xdc\x68\xb7\x49\xeb\x33\xff\x1a\x56\x0c\x9a\xe9\x7f\x38\xd3\x83\x00\xf7\x0f\xc0\x6c\xb6\x4e\x63\xcd\x65\x4a\xd6\xab\x84\x6a\x96\x2f\x87\x4d\x72\x0f\xa1\x32\x63\x3a\x5e\x84\x41\x54\x46\x6a\xd3\xc4\x59\xa4\x17\x2c\x0d\x73\xda\x10\x56\x56\x32\xcd\x98\x25\xc2\x3f\x3e\x23\x05\x34\xca\x34\xd5\xeb\x8c\x3c\x19\x91\x5e\xa7\x53\xe2\x10\xa2\x98\x5e\xab\x94\xc4\x80\x25\x05\x8b\x40\xe1\x30\x30\x5b\x92\x62\x43\x92\x4a\x8d\x64\xc1\x21\x29\x76\x19\x38\x06\x0f\x8d\x46\xce\xc7\xed\xf4\x31\x03\x69\x9a\x35\xe9\x40\x2f\xea\xef\x5a\x33\x03\x2e\x0f\x1a\xc5\x6a\xd5\x0e\x2f\xa9\xca\x2c\x03\x0f\x05\x95\x43\x58\x74\xbe\xe4\xfa\x4c\xa6\xcc\xe7\x4e\x5c\x12\x45\x71\xee\x49\x72\x30\x22\x41\x91\x21\x88\x1f\x78\xcc\xf2\x1d\xcf\x78\xac\xc3\x42\x35\x50\x8e\x30\x91\xb1\x0a\xe3\x62\xdf\x57\x52\x08\x79\x0b\xbb\x12\x88\x85\xc9\x82\x91\x04\x88\x09\xcf\xc0\x10\x2d\x06\x32\x69\x96\x90\xdb\x05\x17\x8c\xcc\x0c\x26\x4f\xe7\x91\xc7\x67\xdf\x96\x9e\x45\xf1\x2f\x63\x7a\xc2\x97\x0c\x8a\x4d\x58\x35\xca\x21\xe9\x1e\x83\x23\x3d\x59\x73\x87\x38\xd8\x83\x8b\x92\x98\x62\xf8\x14\x8e\x60\x4a\xa1\xcc\x3b\xdc\x0d\x2b\x52\x81\x8b\x11\x63\x60\xd8\x3c\x34\xea\x01\xb9\x3f\x58\x4b\x27\x19\x73\x99\x7a\x14\x6b\xf4\xed\xe5\x15\x0a\x04\xd9\x48\x42\x84\x26\x5c\x11\x9e\x12\x6b\x43\x30\xcf\x05\xff\x9d\x65\xb9\xf3\x0a\xac\x59\x8a\x4e\xdb\xc2\xbb\x04\xea\xab\xd2\xd3\x88\x99\x01\xdc\x45\x50\x0d\xef\xd2\x30\xb9\x1a\x78\xc8\xf1\x5a\x29\x40\xfe\x80\xf9\x1e\x16\x24\xa5\x4d\x91\xee\xd0\x6e\x7e\x75\x48\x4a\xf3\xa2\x2a\xd1\x6a\x9d\x2d\xc2\x29\x55\x93\xe5\x4a\x84\xf7\x80\xd9\x87\x15\x24\xa8\xb8\xb5\xf2\x67\x38\xf5\xed\xd7\x27\xd0\x6e\x79\xa2\x17\x7d\x10\x4b\xcb\xd7\x50\x68\xe6\x4c\x85\xe0\x5c\xf2\x13\x09\x51\xe0\x36\xaa\xd8\x6c\x3e\x34\x73\xcf\x36\xec\x7f\x59\xef\x23\x9e\x42\xab\xff\x75\xf2\xf7\x37\xa0\x9c\x91\xf5\xa3\xe4\x69\x18\x04\xc6\x89\xa6\xee\x38\xb9\x8d\xf2\x9a\xc1\x13\xf8\x2e\x04\x26\xcf\xfc\xa6\x09\x48\xc1\xf8\xd9\x81\xd9\xa5\xbe\x80\x59\x04\x8d\xf9\xe9\xc8\x78\xf0\xe9\xb8\x8d\x8f\xd6\x4d\x4f\xc7\xb6\x8f\xed\xa1\xfc\x17\x15\x01\xc9\xf4\x46\x30\x98\x63\x78\xda\x72\xda\x22\xbd\x79\x04\xfa\xd5\xdd\x20\xf0\x99\x3c\x73\xcf\xcd\xff\xb7\xd4\x62\x7a\xb9\x32\xeb\x83\xde\xae\x97\x00\xed\x6c\x87\xb3\x4d\xc7\x6a\xdd\x75\x2d\x1f\x93\x3a\xc2\x24\xff\x6e\xf5\xb7\xbe\xf1\x37\xaa\xc3\xce\x46\xb6\x06\xd7\x17\xc0\x52\x07\x07\x5b\xa5\xf9\xfc\x06\x3b\x65\x58\x2f\xc8\x15\xf3\x8e\x48\xf7\x31\x35\x19\x15\xdb\x57\x8f\x7f\xc9\xa7\xa6\xb0\xf9\x6d\xeb\xdc\x97\x05\x19\x10\xad\xa0\xec\xb1\x49\x46\x16\x34\x4d\x04\xcb\x08\x58\x9b\xb4\x74\xf6\x4a\xaa\x25\xd5\xcf\x32\x22\x67\xe4\xf5\xc5\x3b\xf2\xfc\xa4\xd3\x25\x80\x82\xef\x6c\x25\x41\x8a\x25\x17\x82\x67\x51\x19\x7f\x8e\x53\xa8\x5d\x31\x44\x7b\xb6\xff\xf3\x5b\x72\xf0\x97\x36\x24\x6c\xa6\x71\x21\xb7\xa7\x8b\x99\x94\xdd\x92\x33\xcc\x63\x43\x0b\x95\xc3\xe0\x0c\x5c\x79\xa8\x23\xc1\xda\x97\xab\x8a\xc9\xc3\x8c\xd3\x5d\x45\x6f\xb8\xae\x67\x81\x68\x5f\x02\x23\x36\x58\x40\x48\x79\x0d\x8f\xd7\x0c\x46\x73\x44\x22\x10\xb1\xdd\x93\x56\xe7\xb8\xd5\x39\x99\x74\x4e\xfa\xbd\xa3\x7e\xf7\xe7\xe8\xe7\x6e\x8f\xb4\xb0\x92\xb4\x97\x10\x33\x4c\x7d\xf0\xf8\x74\x7b\x9d\xfe\x11\xa9\xc0\x41\x99\x29\x15\x34\x8d\xd9\x85\x86\xc1\x93\x20\xef\x5a\xe6\xe6\x21\xb9\x3b\x77\x0b\xee\xdf\x3d\x79\x4b\xbd\x3e\x2b\x7b\x35\xbb\xd3\x5b\xd9\x8b\x40\x7f\xfb\xc2\x23\x1f\x22\x38\x52\xae\x28\xd4\xae\x0f\xd1\x92\xae\x0c\x62\x94\xad\x04\xd7\x61\xf0\x1b\x9c\x11\xa0\xa7\xe5\x3c\xd0\x4b\xd5\xbc\x34\xfd\x96\x33\x91\x54\x39\x21\x62\xce\x83\x04\xcd\xca\x8c\x82\xd6\xb1\x24\x91\x60\xe9\x1c\x6a\xf8\x90\x9c\x54\xb9\x96\x01\xb8\x16\xa2\x3a\xdf\x6c\xa1\xdc\xeb\xac\x5f\x24\x80\xe5\x0b\x63\x76\x73\x57\xc7\x84\x90\x79\xf5\xd6\x36\x55\x8b\xd7\xbb\xda\x85\x66\x8e\xa5\x39\x4e\x94\x09\x1e\xb3\xf0\xb8\xe9\xda\x22\x68\xf3\xe0\x0d\x4e\xcd\x7a\x19\x9b\x57\x35\x99\x47\x36\xc2\xde\xad\xd0\x80\x59\x78\x0f\x35\x03\x87\xbe\xf7\x2c\x51\xf4\xd6\xa4\xbc\x73\xc4\x3a\x4d\x98\x12\x74\x13\xfd\x19\xab\x15\xba\xb9\x38\x86\x7a\x3d\xb1\x80\xed\x6d\x8a\x5e\x19\x36\x86\xf1\x29\x5c\x73\x28\x12\xae\x3c\xf3\x7e\xaf\x44\x2b\x75\xfa\xfa\xed\xd1\xb7\xd7\xce\x33\xca\x29\x9e\x83\xb7\x0e\x27\x5b\x53\x7b\x61\xc1\x43\x14\xb0\x9c\x2b\xff\x2c\x61\x01\xea\x99\x34\xc5\xb0\x7e\x03\xf2\x2a\x98\xcc\x84\x20\x53\x46\x28\x39\xb3\x97\x56\x30\x8c\x83\x77\xa0\x02\x47\x8d\xf2\xb2\x80\x25\xbf\xd4\x22\xaa\x1e\x3b\xd6\x40\x85\x1d\xcb\xde\x56\xa7\x8f\xde\xb3\x1b\x1c\x1a\xe6\x3e\xa0\xda\xf0\x5c\x63\xdb\xb1\x73\x41\x64\x9c\x60\x27\x3d\x28\x00\xa6\x59\x05\x93\x2c\xb8\x82\xaa\x96\x82\x71\xa1\x3c\x5e\xb3\x4d\x56\xca\x13\x19\xa1\x8c\x17\xec\x00\x83\x54\x45\x4d\xf3\x46\x18\x2b\x81\xed\xad\x6e\x8b\xc4\xee\x6d\xba\x21\x81\xc2\x09\x87\x97\x73\x0a\xee\xab\x32\xf7\x4a\xaf\x59\x38\x4f\xb5\xe2\xe5\x89\xa7\x46\xe6\x56\x77\x11\x6d\xca\xc0\x34\x27\x17\x73\x1c\xb9\xcc\xab\x66\x89\x16\x4d\x32\xa8\xf7\x06\xfe\x4a\x48\xaa\xfd\x25\x18\xc5\x9b\x57\xcd\x46\x19\x7a\x0f\x36\x6c\x51\xf7\x27\x45\xd9\x33\xe1\x80\x63\x83\xf5\x7c\xe8\xee\x85\x0e\xcd\xbe\x87\x85\x14\xd6\x58\x7d\xf7\x9d\x57\x61\x37\xdd\x77\x21\xcc\x73\xd0\x82\xf1\xf9\x42\xf7\x31\xf4\x73\x50\x0c\x15\xf9\xfa\x94\x0a\x31\xa5\xf1\x75\x9f\xc8\xd4\xc4\xc9\x29\x42\x0b\x2a\x20\x12\x48\xb8\x85\xf6\x6b\xbe\x92\xa3\xe6\x75\xb7\xc4\xc4\xca\x6c\x27\x82\x43\x5f\x5b\xff\x3c\xbf\x55\xd6\x67\x70\x14\xec\x3b\x1d\xab\xba\x39\x6a\x97\x63\x25\x6f\x22\x6f\xcc\xbe\x76\xee\x8b\xc5\x1a\x47\x96\xbc\x19\xd0\x8c\xc0\xaa\xe6\x31\x15\x76\x34\xf2\x46\x96\x92\x45\x18\xd3\xf4\x86\x82\xc3\xa9\x62\xb0\xaf\xf3\x81\x05\x46\x20\x91\xb8\xc0\x83\x13\x38\x24\x50\xf3\x29\x0d\x7b\xc7\xc7\x70\xf2\xef\xf4\xdc\x47\x27\x3a\x69\x06\xb5\xf0\xb3\xdb\x7b\x11\x64\x00\xb9\x6f\x31\x70\xef\x30\x63\xe0\xac\x79\x26\x97\xff\x3e\x95\x52\x25\x16\x25\x32\x13\x62\x5e\x93\xef\xc8\x78\x64\x84\x8a\xee\xc8\x8f\x3f\x02\xcd\xb0\x78\x3d\xb0\x0f\xb7\x65\x40\x7a\xf2\xbe\x67\x30\x3b\xdc\x59\x7d\xa2\x0d\xc8\xe9\x1e\x17\xfe\xe9\xd5\xd6\xab\xc2\x1c\xbe\xfb\xad\x2c\x87\x04\x58\xac\xa0\x5b\xe7\xe3\xb0\xbb\x88\x9b\x28\x70\x2f\x4f\xe7\x13\x39\x9f\x0b\x16\xee\xe6\x53\xc4\xc7\x16\x2f\xe8\x0c\xf2\xf6\x75\x02\xef\x19\xc3\x3c\xc3\x49\xa2\xbc\xb0\x48\xf2\xb8\xc7\x71\xf9\xce\xcb\x0c\x4b\x4c\xfe\xf8\xc3\xb1\xf1\x0a\x44\x07\xa1\x4f\xea\x25\xe9\x13\x55\xc3\x9c\x98\x47\x8e\x11\x4c\x39\x11\x02\x06\x6e\xb1\x4c\x55\x40\xd9\xaa\x90\xf8\x71\x69\xee\x26\x2e\xad\x1a\x57\x79\xdd\xda\x2e\x12\xce\x5e\xa1\xb9\x79\xf0\xf8\x56\x22\xf9\x0b\xcf\xd5\x55\x57\x80\xa4\x5a\xad\x99\x5f\xfa\xf7\x38\x6b\x97\x23\x81\xfa\x49\x0d\x34\xf8\x1a\xd2\xbd\x4e\x67\x26\x04\x80\xff\x8c\x42\xd2\xef\x10\x6f\xdb\x36\x45\x73\xaa\x8b\x84\x4e\xae\x31\xde\xe3\xe2\xea\x45\xb8\xbd\x98\x99\xc0\x34\x8e\xa1\x65\xce\xbc\x70\xe4\x0d\xe0\xf3\x6f\x17\xef\xde\xc2\xb8\xa3\x80\x39\x9f\x6d\xc2\x8a\x83\x4a\x36\x9e\x16\xce\xc6\xc5\xd2\x3f\x15\x5e\xe6\xc0\xc4\xe5\xde\xdb\xc8\xd5\x2b\xf5\x67\x6e\x50\xc6\x1d\xeb\x6b\xef\x66\x33\x18\x51\x5e\x6e\x34\xf3\x86\xb7\x92\xef\x23\x27\xb7\xfd\x96\xfe\x3a\x93\x1d\x0a\xed\x29\x8b\x96\xfb\x06\x17\x20\x3b\x7f\x6f\xa8\xf8\x0d\x49\x30\x51\x1d\xce\x60\x0f\x2d\xfe\xa4\xb0\x4d\xd7\xdd\xa2\xfb\xba\x33\x9f\xf7\xeb\xa1\xb9\x62\x1b\x37\xa2\xea\x0f\x80\xc4\xbe\x1b\x95\xcb\xde\xfc\xd4\x10\x47\x4e\x03\xb3\xb8\xa4\x6a\xce\x53\x5c\x5d\xdd\x79\x1f\x3d\xf8\x40\xe4\xc8\x8f\x6c\x7b\xc8\x86\x2d\x5a\x33\xba\xe4\x62\xd3\x27\x4b\x99\xca\x0c\xce\x8d\x6c\x90\xaf\xe0\xad\x65\x9f\x9c\xac\xb4\x4f\x5d\xd8\xca\x70\x98\x42\xef\x61\xaa\xa5\xe5\x0a\xb6\x85\xbd\x40\x63\x9e\x90\x1f\x8e\x8e\x8e\xac\x78\xfe\x7d\x3d\x29\xde\x2c\x29\x24\xe6\x5c\x49\xe8\xfc\xad\x58\x0a\xa9\xfa\xe4\x07\x36\x63\x55\x32\x12\x4d\xa1\x20\x20\xf6\x4a\x66\x1c\xad\xdb\x87\xe8\x10\x54\xf3\x1b\x83\x59\x43\xfc\xc9\xa0\x26\x1c\xce\xbc\x14\x34\xe2\x29\x76\xed\xd6\x54\xc8\xf8\xba\xa6\xd4\x73\xa7\x54\x95\x3e\x72\xb7\xa4\xd6\x9a\xe5\x45\xe7\x5f\x3b\xb9\x09\xb7\xf1\x61\x20\xdb\x2b\xa0\x65\x92\x0f\x4f\x27\xc8\x63\xa7\xde\xcf\x29\x45\xee\x10\x09\x36\x00\x86\x6d\xf7\xfb\x71\xdb\xfe\x9c\xfd\x5f\xeb\x25\x49\x71\xd6\x1e\x00\x00")
 
 func static_index_html_bytes() ([]byte, error) {
 	return bindata_read(
@@ -100,7 +100,7 @@ func static_index_html() (*asset, error) {
 		return nil, err
 	}
 
-	info := bindata_file_info{name: "static/index.html", size: 6101, mode: os.FileMode(420), modTime: time.Unix(1462829575, 0)}
+	info := bindata_file_info{name: "static/index.html", size: 7894, mode: os.FileMode(420), modTime: time.Unix(1792202596, 0)}
 	a := &asset{bytes: bytes, info: info}
 	return a, nil
 }
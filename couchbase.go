@@ -0,0 +1,472 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// From couchbase.log, which is the concatenated output of many system
+// commands, where each command's output has a header section of a
+// description and the command line...
+//   ==============================================================================
+//   Filesystem
+//   df -ha
+//   ==============================================================================
+//   Filesystem      Size  Used Avail Use% Mounted on
+//   /dev/xvda1       20G   18G  2.0G  90% /
+
+// couchbaseSection is the output of a command from couchbase.log.
+type couchbaseSection struct {
+	p        *fileProcessor
+	ts       string
+	module   string
+	command  string
+	lines    []string
+	offsets  []int64 // The byte offset of each line.
+	lineNums []int64
+}
+
+// couchbaseSectionParsers are keyed by the section's module, which
+// is the command's name, and emit the useful facts of a command's
+// output as VALS.
+var couchbaseSectionParsers = map[string]func(s *couchbaseSection){
+	"df":     processDfSection,
+	"free":   processFreeSection,
+	"vmstat": processVmstatSection,
+	"top":    processTopSection,
+}
+
+// processCouchbaseEntry processes a couchbase.log entry, which is
+// either a section's header or a command's output, where the output
+// is emitted as FULL with the command's name as the module.
+func processCouchbaseEntry(p *fileProcessor, startOffset, startLine int64, lines []string) {
+	s := p.sectionOutput(startOffset, startLine, lines)
+	if s == nil || len(s.lines) <= 0 {
+		return
+	}
+
+	module, ol := emitCommonPrep(s.module, p.fnameBase, s.offsets[0], s.lineNums[0])
+
+	p.run.emitEntryFull(s.ts, module, "", p.dirBase,
+		p.fname, p.fnameBase, p.fnameOut, ol, s.offsets[0], s.lineNums[0], s.lines)
+
+	if parser := couchbaseSectionParsers[s.module]; parser != nil {
+		parser(s)
+	}
+}
+
+// sectionCommands are the usual commands of the sections of files
+// like couchbase.log and ddocs.log, so that their headers can be
+// recognized.
+var sectionCommands = map[string]bool{
+	"cat": true, "curl": true, "df": true, "dmesg": true, "free": true,
+	"ifconfig": true, "iptables": true, "ls": true, "lsof": true,
+	"mount": true, "netstat": true, "ps": true, "sysctl": true, "top": true,
+	"ulimit": true, "uname": true, "uptime": true, "vmstat": true,
+}
+
+// isSectionHeader returns true when an entry is recognizably a
+// section's header, like "=====", "Filesystem", "df -ha".
+func isSectionHeader(lines []string) bool {
+	return len(lines) == 3 && sectionCommands[commandModule(lines[2])]
+}
+
+// sectionOutput tracks the "=====" sections of files like
+// couchbase.log, where an entry is either a section's header, which
+// has the description and command, or the command's output.  It
+// returns nil for a header.  A header that's missing its description
+// or command still counts as a header, and a recognizable header
+// where output was expected re-syncs the tracking.
+func (p *fileProcessor) sectionOutput(startOffset, startLine int64,
+	lines []string) *couchbaseSection {
+	if p.state["expect"] != "output" || isSectionHeader(lines) {
+		p.state["command"] = ""
+		if len(lines) >= 2 {
+			p.state["command"] = lines[len(lines)-1] // Ex: "df -ha".
+		}
+		p.state["expect"] = "output"
+		return nil
+	}
+
+	p.state["expect"] = ""
+
+	s := &couchbaseSection{
+		p:       p,
+		ts:      p.collectTS,
+		module:  commandModule(p.state["command"]),
+		command: p.state["command"],
+	}
+
+	offset := startOffset
+	for i, line := range lines {
+		if i > 0 { // Skip the "=====" line.
+			s.lines = append(s.lines, line)
+			s.offsets = append(s.offsets, offset)
+			s.lineNums = append(s.lineNums, startLine+int64(i))
+		}
+		offset += int64(len(line)) + 1
+	}
+
+	return s
+}
+
+// commandModule returns the name of a command, like "ifconfig" from
+// "/sbin/ifconfig -a", for use as a module.
+func commandModule(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) <= 0 {
+		return ""
+	}
+
+	return filepath.Base(strings.Trim(fields[0], `[]'",`))
+}
+
+// emit emits a name=value pair from the i'th line of the section.
+func (s *couchbaseSection) emit(i int, namePath []string, name, val string) {
+	if val == "" || val == "-" {
+		return
+	}
+
+	module, ol := emitCommonPrep(s.module, s.p.fnameBase, s.offsets[i], s.lineNums[i])
+
+	s.p.emitVal(s.offsets[i], s.lineNums[i], ol, s.ts, module, "", namePath, name, val)
+}
+
+// statName returns a name from a column header, like "use_pct" from
+// "Use%" or "cpu_pct" from "%CPU".
+func statName(header string) string {
+	name := strings.ToLower(header)
+
+	if strings.HasPrefix(name, "%") {
+		name = name[1:] + "_pct"
+	} else if strings.HasSuffix(name, "%") {
+		name = name[0:len(name)-1] + "_pct"
+	}
+
+	return strings.Replace(name, " ", "_", -1)
+}
+
+// sizeVal returns a size as a BYTES value for emitVal, where a plain
+// number is in the given unit, like "3924700" KiB as "3924700KB", and
+// a suffixed size, like top's "1.2g", is in powers of 1024, as "1.2GB".
+func sizeVal(val, unit string) string {
+	if re_int_only.MatchString(val) {
+		return val + unit
+	}
+	if upper := strings.ToUpper(val); re_df_size.MatchString(upper) {
+		return upper + "B"
+	}
+	return val
+}
+
+// percentVal returns a number that's a percentage, like vmstat's "97"
+// or top's "5.0", as a PERCENT value for emitVal, like "97%".
+func percentVal(val string) string {
+	if re_int_only.MatchString(val) || re_float_only.MatchString(val) {
+		return val + "%"
+	}
+	return val
+}
+
+// ------------------------------------------------------------
+
+// From df, where a long filesystem name wraps onto its own line...
+//   Filesystem      Size  Used Avail Use% Mounted on
+//   /dev/xvda1       20G   18G  2.0G  90% /
+//   /dev/mapper/vg_couchbase-lv_data
+//                   100G   40G   60G  40% /data
+
+// re_df_size matches the sizes of df -h, like "20G", which are in
+// powers of 1024.
+var re_df_size = regexp.MustCompile(`^\d+(\.\d+)?[KMGTP]$`)
+
+// processDfSection emits each filesystem's columns, with a path of
+// the filesystem's mount point, where the sizes are BYTES and the
+// Use% is a PERCENT.
+func processDfSection(s *couchbaseSection) {
+	var header []string
+	var pending []string
+
+	for i, line := range s.lines {
+		fields := strings.Fields(line)
+		if len(fields) <= 0 {
+			continue
+		}
+
+		if fields[0] == "Filesystem" {
+			header = nil
+			for j := 0; j < len(fields); j++ {
+				if fields[j] == "Mounted" && j+1 < len(fields) && fields[j+1] == "on" {
+					header = append(header, "mounted_on")
+					break
+				}
+				header = append(header, statName(fields[j]))
+			}
+			pending = nil
+			continue
+		}
+
+		if header == nil {
+			continue
+		}
+
+		fields = append(pending, fields...)
+		if len(fields) < len(header) {
+			pending = fields
+			continue
+		}
+		pending = nil
+
+		mount := strings.Join(fields[len(header)-1:], " ")
+
+		for j := 1; j < len(header)-1; j++ {
+			val := fields[j]
+			if re_df_size.MatchString(val) {
+				val = val + "B" // Ex: "20G" is a BYTES of "20GB".
+			}
+
+			s.emit(i, []string{mount}, header[j], val)
+		}
+	}
+}
+
+// ------------------------------------------------------------
+
+// From free...
+//                total       used       free     shared    buffers     cached
+//   Mem:       3924700    3716328     208372          0     175048    2394560
+//   -/+ buffers/cache:    1146720    2777980
+//   Swap:      4063228      63636    3999592
+
+// processFreeSection emits each row's columns, with a path of the
+// row's label, like "[mem]", where the sizes are BYTES.
+func processFreeSection(s *couchbaseSection) {
+	unit := freeUnit(s.command)
+
+	var header []string
+
+	for i, line := range s.lines {
+		colon := strings.Index(line, ":")
+		if colon < 0 {
+			fields := strings.Fields(line)
+			if len(fields) > 0 && fields[0] == "total" {
+				header = nil
+				for _, field := range fields {
+					header = append(header, statName(field))
+				}
+			}
+			continue
+		}
+
+		if header == nil {
+			continue
+		}
+
+		label := strings.ToLower(strings.TrimSpace(line[0:colon]))
+
+		names := header
+		if strings.HasPrefix(label, "-/+ ") { // Only has used and free.
+			label, names = label[4:], header[1:]
+		}
+
+		for j, val := range strings.Fields(line[colon+1:]) {
+			if j < len(names) {
+				s.emit(i, []string{label}, names[j], sizeVal(val, unit))
+			}
+		}
+	}
+}
+
+// freeUnit returns the unit of free's sizes from its options, like
+// "MB" for "free -tm", where the default is KiB.
+func freeUnit(command string) string {
+	for _, field := range strings.Fields(command) {
+		if !strings.HasPrefix(field, "-") || strings.HasPrefix(field, "--") {
+			continue
+		}
+		switch {
+		case strings.Contains(field, "b"):
+			return "B"
+		case strings.Contains(field, "m"):
+			return "MB"
+		case strings.Contains(field, "g"):
+			return "GB"
+		}
+	}
+	return "KB"
+}
+
+// ------------------------------------------------------------
+
+// From vmstat...
+//   procs -----------memory---------- ---swap-- -----io---- -system-- ------cpu-----
+//    r  b   swpd   free   buff  cache   si   so    bi    bo   in   cs us sy id wa st
+//    1  0  63636 208068 175048 2394560    0    0     5    18    1    1  2  1 97  0  0
+
+// vmstatSizes and vmstatPercents are the vmstat columns that are
+// sizes in KiB and percentages of cpu time.
+var vmstatSizes = map[string]bool{
+	"swpd": true, "free": true, "buff": true, "cache": true,
+	"inact": true, "active": true,
+}
+
+var vmstatPercents = map[string]bool{
+	"us": true, "sy": true, "id": true, "wa": true, "st": true,
+}
+
+// processVmstatSection emits the columns of each sample row, where
+// the memory columns are BYTES and the cpu columns are PERCENT.
+func processVmstatSection(s *couchbaseSection) {
+	var header []string
+
+	for i, line := range s.lines {
+		fields := strings.Fields(line)
+		if len(fields) <= 0 {
+			continue
+		}
+
+		if fields[0] == "r" {
+			header = fields
+			continue
+		}
+
+		if len(fields) != len(header) || !re_int_only.MatchString(fields[0]) {
+			continue
+		}
+
+		for j, val := range fields {
+			if vmstatSizes[header[j]] {
+				val = sizeVal(val, "KB")
+			} else if vmstatPercents[header[j]] {
+				val = percentVal(val)
+			}
+
+			s.emit(i, nil, header[j], val)
+		}
+	}
+}
+
+// ------------------------------------------------------------
+
+// From top, which has summary lines followed by a table of processes
+// or threads...
+//   top - 06:26:40 up 10 days,  3:02,  1 user,  load average: 0.08, 0.12, 0.10
+//   Tasks: 180 total,   1 running, 179 sleeping,   0 stopped,   0 zombie
+//   Cpu(s):  2.1%us,  0.9%sy,  0.0%ni, 96.6%id,  0.3%wa,  0.0%hi,  0.0%si,  0.0%st
+//   KiB Mem :  3924700 total,   208372 free,  3716328 used,  2569608 buff/cache
+//   KiB Swap:  4063228 total,  3999592 free,    63636 used.  2777980 avail Mem
+//
+//     PID USER      PR  NI    VIRT    RES    SHR S %CPU %MEM     TIME+ COMMAND
+//    1234 couchba+  20   0 1048576 524288  10240 S  5.0 13.2  10:01.02 beam.smp
+
+var re_top_load = regexp.MustCompile(`load average:\s*([\d.]+),?\s+([\d.]+),?\s+([\d.]+)`)
+
+var re_top_summary = regexp.MustCompile(`(\d[\d.]*[kmgtKMGT]?)(%?)\s*([a-zA-Z][\w/]*( Mem)?)`)
+
+// re_top_size_label matches the label of a summary line of sizes,
+// like "KiB Mem", whose first word is the unit.
+var re_top_size_label = regexp.MustCompile(`^([KMGT])iB `)
+
+// topProcessNames are the process columns that are emitted, where
+// virt, res and shr are sizes in KiB and the rest are percentages.
+var topProcessNames = map[string]bool{
+	"virt": true, "res": true, "shr": true, "cpu_pct": true, "mem_pct": true,
+}
+
+// processTopSection emits the summary lines with paths like "[mem]",
+// and the non-idle processes with a path of the command and pid,
+// where sizes are BYTES and percentages are PERCENT.
+func processTopSection(s *couchbaseSection) {
+	var header []string
+
+	for i, line := range s.lines {
+		fields := strings.Fields(line)
+		if len(fields) <= 0 {
+			continue
+		}
+
+		if header == nil {
+			if fields[0] == "PID" {
+				for _, field := range fields {
+					header = append(header, statName(field))
+				}
+				continue
+			}
+
+			if m := re_top_load.FindStringSubmatch(line); m != nil {
+				s.emit(i, []string{"load"}, "1min", m[1])
+				s.emit(i, []string{"load"}, "5min", m[2])
+				s.emit(i, []string{"load"}, "15min", m[3])
+				continue
+			}
+
+			colon := strings.Index(line, ":")
+			if colon < 0 {
+				continue
+			}
+
+			unit := ""
+			if m := re_top_size_label.FindStringSubmatch(line); m != nil {
+				unit = m[1] + "B"
+			}
+
+			label := re_top_size_label.ReplaceAllString(line[0:colon], "")
+			label = strings.TrimPrefix(strings.ToLower(label), "%")
+			label = strings.TrimSpace(strings.Replace(label, "(s)", "", -1))
+
+			for _, m := range re_top_summary.FindAllStringSubmatch(line[colon+1:], -1) {
+				val := m[1]
+				if m[2] == "%" {
+					val = percentVal(val)
+				} else if unit != "" {
+					val = sizeVal(val, unit)
+				}
+
+				s.emit(i, []string{label}, statName(m[3]), val)
+			}
+			continue
+		}
+
+		if len(fields) < len(header) {
+			continue
+		}
+
+		command := strings.Join(fields[len(header)-1:], " ")
+
+		vals := map[string]string{}
+		for j, name := range header[0 : len(header)-1] {
+			vals[name] = fields[j]
+		}
+
+		// Idle processes are skipped, as they're usually the majority.
+		if strings.Trim(vals["cpu_pct"], "0.") == "" &&
+			strings.Trim(vals["mem_pct"], "0.") == "" {
+			continue
+		}
+
+		for j, name := range header[0 : len(header)-1] {
+			if topProcessNames[name] {
+				val := fields[j]
+				if strings.HasSuffix(name, "_pct") {
+					val = percentVal(val)
+				} else {
+					val = sizeVal(val, "KB")
+				}
+
+				s.emit(i, []string{command, vals["pid"]}, name, val)
+			}
+		}
+	}
+}
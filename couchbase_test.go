@@ -0,0 +1,170 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var sep = strings.Repeat("=", 78)
+
+func TestCouchbaseSections(t *testing.T) {
+	tests := []struct {
+		about   string
+		content []string
+		exp     []string
+	}{
+		{"df sizes are BYTES and Use% is a PERCENT",
+			[]string{
+				sep, "Filesystem", "df -ha",
+				sep,
+				"Filesystem      Size  Used Avail Use% Mounted on",
+				"/dev/xvda1       20G   18G  2.0G  90% /",
+				"proc               0     0     0    - /proc",
+			},
+			[]string{
+				"[/] size = BYTES 21474836480",
+				"[/] used = BYTES 19327352832",
+				"[/] avail = BYTES 2147483648",
+				"[/] use_pct = PERCENT 0.9",
+				"[/proc] size = INT 0",
+				"[/proc] used = INT 0",
+				"[/proc] avail = INT 0",
+			},
+		},
+		{"a header without a description still alternates",
+			[]string{
+				sep, "uname -a",
+				sep, "Linux cb1 3.10.0 #1 SMP x86_64 GNU/Linux",
+				sep, "Memory", "free -t",
+				sep,
+				"             total       used",
+				"Mem:       3924700    3716328",
+			},
+			[]string{
+				"[mem] total = BYTES 4018892800",
+				"[mem] used = BYTES 3805519872",
+			},
+		},
+		{"a recognizable header re-syncs after a missing output",
+			[]string{
+				sep, "Memory", "free -t",
+				sep, "Filesystem", "df -ha",
+				sep,
+				"Filesystem      Size  Used Avail Use% Mounted on",
+				"/dev/xvda1       20G   18G  2.0G  90% /",
+			},
+			[]string{
+				"[/] size = BYTES 21474836480",
+				"[/] used = BYTES 19327352832",
+				"[/] avail = BYTES 2147483648",
+				"[/] use_pct = PERCENT 0.9",
+			},
+		},
+		{"free sizes are BYTES in KiB, or in the unit of its options",
+			[]string{
+				sep, "Memory", "free -t",
+				sep,
+				"             total       used       free",
+				"Mem:       3924700    3716328     208372",
+				"-/+ buffers/cache:    1146720    2777980",
+				sep, "Memory", "free -tm",
+				sep,
+				"             total       used       free",
+				"Swap:         3967         62       3905",
+			},
+			[]string{
+				"[mem] total = BYTES 4018892800",
+				"[mem] used = BYTES 3805519872",
+				"[mem] free = BYTES 213372928",
+				"[buffers/cache] used = BYTES 1174241280",
+				"[buffers/cache] free = BYTES 2844651520",
+				"[swap] total = BYTES 4159700992",
+				"[swap] used = BYTES 65011712",
+				"[swap] free = BYTES 4094689280",
+			},
+		},
+		{"vmstat memory is BYTES and cpu is PERCENT",
+			[]string{
+				sep, "Virtual memory", "vmstat 1 2",
+				sep,
+				"procs -----------memory---------- ---swap-- -----io---- -system-- ------cpu-----",
+				" r  b   swpd   free   buff  cache   si   so    bi    bo   in   cs us sy id wa st",
+				" 1  0  63636 208068 175048 2394560    0    0     5    18    1    1  2  1 97  0  0",
+			},
+			[]string{
+				"[] r = INT 1",
+				"[] b = INT 0",
+				"[] swpd = BYTES 65163264",
+				"[] free = BYTES 213061632",
+				"[] buff = BYTES 179249152",
+				"[] cache = BYTES 2452029440",
+				"[] si = INT 0",
+				"[] so = INT 0",
+				"[] bi = INT 5",
+				"[] bo = INT 18",
+				"[] in = INT 1",
+				"[] cs = INT 1",
+				"[] us = PERCENT 0.02",
+				"[] sy = PERCENT 0.01",
+				"[] id = PERCENT 0.97",
+				"[] wa = PERCENT 0",
+				"[] st = PERCENT 0",
+			},
+		},
+		{"top sizes are BYTES and percentages are PERCENT",
+			[]string{
+				sep, "Top threads", "top -Hb -n1",
+				sep,
+				"top - 06:26:40 up 10 days,  3:02,  1 user,  load average: 0.08, 0.12, 0.10",
+				"Tasks: 180 total,   1 running",
+				"Cpu(s):  2.1%us, 96.6%id",
+				"KiB Mem :  3924700 total,   208372 free",
+				"",
+				"  PID USER      PR  NI    VIRT    RES    SHR S %CPU %MEM     TIME+ COMMAND",
+				" 1234 couchba+  20   0 1048576   1.2g  10240 S  5.0 13.2  10:01.02 beam.smp",
+				" 1235 couchba+  20   0    2048   1024    512 S  0.0  0.0   0:00.01 idle",
+			},
+			[]string{
+				"[load] 1min = FLOAT 0.08",
+				"[load] 5min = FLOAT 0.12",
+				"[load] 15min = FLOAT 0.10",
+				"[tasks] total = INT 180",
+				"[tasks] running = INT 1",
+				"[cpu] us = PERCENT 0.021",
+				"[cpu] id = PERCENT 0.966",
+				"[mem] total = BYTES 4018892800",
+				"[mem] free = BYTES 213372928",
+				"[beam.smp 1234] virt = BYTES 1073741824",
+				"[beam.smp 1234] res = BYTES 1288490188",
+				"[beam.smp 1234] shr = BYTES 10485760",
+				"[beam.smp 1234] cpu_pct = PERCENT 0.05",
+				"[beam.smp 1234] mem_pct = PERCENT 0.132",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		p, buf := testFileProcessor("couchbase.log", FileMetas["couchbase.log"],
+			strings.Join(test.content, "\n")+"\n", "VALS", "INT,FLOAT,BYTES,PERCENT")
+
+		if err := p.process(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := emittedVals(buf); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("%s, got: %q, expected: %q", test.about, got, test.exp)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// From ddocs.log, which has the design doc definitions of each
+// bucket, as the JSON output of a REST request per bucket...
+//   ==============================================================================
+//   Couchbase design docs (default)
+//   curl -sS -u Administrator:***** http://127.0.0.1:8091/pools/default/buckets/default/ddocs
+//   ==============================================================================
+//   {"rows":[{"doc":{"meta":{"id":"_design/dev_beers","rev":"1-5e6a2a02"},
+//     "json":{"views":{"by_name":{"map":"function (doc, meta) { ... }"}}}}}]}
+
+var re_ddocs_bucket = regexp.MustCompile(`/buckets/([^/\s]+)/ddocs`)
+
+type ddocsRows struct {
+	Rows []struct {
+		Doc struct {
+			Meta struct {
+				ID  string `json:"id"`
+				Rev string `json:"rev"`
+			} `json:"meta"`
+			JSON struct {
+				Views   map[string]map[string]interface{} `json:"views"`
+				Spatial map[string]interface{}            `json:"spatial"`
+			} `json:"json"`
+		} `json:"doc"`
+	} `json:"rows"`
+}
+
+// processDdocsEntry processes a ddocs.log entry, where a bucket's
+// design docs are emitted as FULL, and as VALS with a path of the
+// bucket and design doc id, like "[default _design/dev_beers]".
+func processDdocsEntry(p *fileProcessor, startOffset, startLine int64, lines []string) {
+	s := p.sectionOutput(startOffset, startLine, lines)
+	if s == nil || len(s.lines) <= 0 {
+		return
+	}
+
+	s.module = "" // Use the file's usual module instead of "curl".
+
+	bucket := ""
+	if m := re_ddocs_bucket.FindStringSubmatch(s.command); m != nil {
+		bucket = m[1]
+	}
+
+	module, ol := emitCommonPrep(s.module, p.fnameBase, s.offsets[0], s.lineNums[0])
+
+	p.run.emitEntryFull(s.ts, module, "", p.dirBase,
+		p.fname, p.fnameBase, p.fnameOut, ol, s.offsets[0], s.lineNums[0], s.lines)
+
+	var ddocs ddocsRows
+
+	err := json.Unmarshal([]byte(strings.Join(s.lines, "\n")), &ddocs)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s/%s:%d, ddocs JSON, err: %v\n",
+			p.dirBase, p.fname, s.lineNums[0], err)
+		return
+	}
+
+	for _, row := range ddocs.Rows {
+		namePath := []string{bucket, row.Doc.Meta.ID}
+
+		s.emit(0, namePath, "rev", row.Doc.Meta.Rev)
+		s.emit(0, namePath, "views", strconv.Itoa(len(row.Doc.JSON.Views)))
+		s.emit(0, namePath, "spatial", strconv.Itoa(len(row.Doc.JSON.Spatial)))
+
+		for _, viewName := range sortedKeys(row.Doc.JSON.Views) {
+			view := row.Doc.JSON.Views[viewName]
+
+			viewPath := []string{bucket, row.Doc.Meta.ID, viewName}
+
+			if reduce, ok := view["reduce"].(string); ok {
+				s.emit(0, viewPath, "reduce", reduce)
+			}
+
+			if m, ok := view["map"].(string); ok {
+				s.emit(0, viewPath, "mapBytes", strconv.Itoa(len(m)))
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProcessDdocsEntry(t *testing.T) {
+	ddocsSection := func(bucket string, json ...string) []string {
+		return append([]string{sep,
+			"Couchbase design docs (" + bucket + ")",
+			"curl -sS -u Administrator:***** http://127.0.0.1:8091/pools/default/buckets/" +
+				bucket + "/ddocs",
+			sep}, json...)
+	}
+
+	tests := []struct {
+		lines []string
+		exp   []string
+	}{
+		{ddocsSection("default", `{"rows":[{"doc":{"meta":{"id":"_design/dev_beers","rev":"1-5e6a2a02"},`,
+			`"json":{"views":{"by_name":{"map":"function (doc, meta) { emit(doc.name); }","reduce":"_count"},`,
+			`"by_abv":{"map":"function (doc) { emit(doc.abv); }"}}}}}]}`),
+			[]string{`[default _design/dev_beers] rev = STRING "1-5e6a2a02"`,
+				"[default _design/dev_beers] views = INT 2",
+				"[default _design/dev_beers] spatial = INT 0",
+				"[default _design/dev_beers by_abv] mapBytes = INT 33",
+				`[default _design/dev_beers by_name] reduce = STRING "_count"`,
+				"[default _design/dev_beers by_name] mapBytes = INT 40"}},
+		{ddocsSection("beer-sample", `{"rows":[{"doc":{"meta":{"id":"_design/geo","rev":"2-aa"},`,
+			`"json":{"spatial":{"points":"function (doc) {}"}}}}]}`),
+			[]string{`[beer-sample _design/geo] rev = STRING "2-aa"`,
+				"[beer-sample _design/geo] views = INT 0",
+				"[beer-sample _design/geo] spatial = INT 1"}},
+		{ddocsSection("empty", `{"rows":[]}`), nil},
+		// Bad JSON is still emitted as FULL, but has no VALS.
+		{ddocsSection("default", `{"rows":[{"doc":`), nil},
+	}
+
+	for i, test := range tests {
+		content := strings.Join(test.lines, "\n") + "\n"
+
+		p, buf := testFileProcessor("ddocs.log", FileMetas["ddocs.log"], content,
+			"FULL,VALS", "INT,STRING")
+		p.collectTS = "2016-05-06T06:26:39.000Z"
+
+		if err := p.process(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := emittedVals(buf); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("test %d, got: %q, expected: %q", i, got, test.exp)
+		}
+
+		if !strings.Contains(buf.String(), test.lines[4]) {
+			t.Errorf("test %d, got: %q, expected FULL of: %q", i, buf.String(), test.lines[4])
+		}
+	}
+}
@@ -13,6 +13,11 @@ func MakeHistogram() *ghistogram.Histogram {
 
 // ------------------------------------------------------------
 
+// DictEntryMaxVals bounds the number of distinct values that are
+// counted by a DictEntry, as big log files can have many, many unique
+// values, like timestamps or uuids.
+var DictEntryMaxVals = 1000
+
 // Dict represents a mapping of names to DictEntry's.
 type Dict map[string]*DictEntry
 
@@ -20,10 +25,23 @@ type DictEntry struct {
 	Kind string // For exmaple, "INT" or "STRING".
 	Seen uint64 // Count of number of times this entry was seen.
 
-	// When the Kind is "STRING", sub-dictionary of value counts.
+	// When the Kind is "STRING", "BOOL" or a semantic kind, like "NODE",
+	// sub-dictionary of value counts.
 	Vals map[string]uint64 `json:"Vals,omitempty"`
 
 	IntHistogram *ghistogram.Histogram `json:"IntHistogram,omitempty"`
+
+	// When the Kind is numeric, like "INT", "FLOAT" or "DURATION", the
+	// range of the values, including the negative and fractional
+	// values that the IntHistogram doesn't count.
+	Min *float64 `json:"Min,omitempty"`
+	Max *float64 `json:"Max,omitempty"`
+}
+
+// numericKinds are the kinds whose values are numbers, where the
+// DURATION, BYTES and PERCENT values are in base units.
+var numericKinds = map[string]bool{
+	"INT": true, "FLOAT": true, "DURATION": true, "BYTES": true, "PERCENT": true,
 }
 
 func MakeDictEntry(kind string) *DictEntry {
@@ -43,8 +61,40 @@ func (dict Dict) AddDictEntry(kind string, name, val string) {
 
 	de.Seen++
 
-	if kind == "STRING" {
-		de.Vals[val]++
+	// The values of other kinds, like UD's user data, aren't counted.
+	if kind == "STRING" || kind == "BOOL" || semTypes[kind] {
+		de.addVal(val, 1)
+	}
+
+	// The values of a semantic kind are also counted across all names
+	// under the kind's own entry, like "<NODE>", which can't be a name,
+	// so the nodes or pids of an incident can be listed directly.
+	if semTypes[kind] {
+		kde := dict["<"+kind+">"]
+		if kde == nil {
+			kde = MakeDictEntry(kind)
+			dict["<"+kind+">"] = kde
+		}
+
+		kde.Seen++
+		kde.addVal(val, 1)
+	}
+
+	if numericKinds[kind] {
+		f, err := strconv.ParseFloat(val, 64)
+		if err == nil {
+			de.addRange(f, f)
+		}
+
+		// A PERCENT's ratio is counted only as a whole percentage, so
+		// the IntHistogram has useful bins, and a ratio of "0" or "1"
+		// isn't also counted as an INT below.
+		if kind == "PERCENT" {
+			if err == nil && f >= 0 {
+				de.IntHistogram.Add(uint64(f*100+0.5), 1)
+			}
+			return
+		}
 	}
 
 	v, err := strconv.ParseInt(val, 10, 64)
@@ -64,8 +114,28 @@ func (src Dict) AddTo(dst Dict) {
 
 		dstDE.Seen += srcDE.Seen
 		for v, vi := range srcDE.Vals {
-			dstDE.Vals[v] += vi
+			dstDE.addVal(v, vi)
 		}
 		dstDE.IntHistogram.AddAll(srcDE.IntHistogram)
+
+		if srcDE.Min != nil && srcDE.Max != nil {
+			dstDE.addRange(*srcDE.Min, *srcDE.Max)
+		}
+	}
+}
+
+func (de *DictEntry) addVal(val string, n uint64) {
+	_, exists := de.Vals[val]
+	if exists || len(de.Vals) < DictEntryMaxVals {
+		de.Vals[val] += n
+	}
+}
+
+func (de *DictEntry) addRange(min, max float64) {
+	if de.Min == nil || min < *de.Min {
+		de.Min = &min
+	}
+	if de.Max == nil || max > *de.Max {
+		de.Max = &max
 	}
 }
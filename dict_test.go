@@ -0,0 +1,62 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestAddDictEntryHistogram(t *testing.T) {
+	tests := []struct {
+		kind            string
+		vals            []string
+		expCount        uint64
+		expMin, expMax  float64
+		expDistinctVals int
+	}{
+		{"INT", []string{"0", "1", "22", "-3"}, 3, -3, 22, 0},
+		{"FLOAT", []string{"2.5", "0"}, 1, 0, 2.5, 0},
+		{"DURATION", []string{"1500000000"}, 1, 1500000000, 1500000000, 0},
+		// A PERCENT's ratio is counted once, as a whole percentage,
+		// including the ratios of "0" and "1" that also parse as INT's.
+		{"PERCENT", []string{"0", "1", "0.93", "-0.1"}, 3, -0.1, 1, 0},
+		{"STRING", []string{`"a"`, `"b"`, `"a"`}, 0, 0, 0, 2},
+	}
+
+	for i, test := range tests {
+		dict := Dict{}
+		for _, val := range test.vals {
+			dict.AddDictEntry(test.kind, "x", val)
+		}
+
+		de := dict["x"]
+		if de.Seen != uint64(len(test.vals)) {
+			t.Errorf("test %d, got seen: %d, expected: %d", i, de.Seen, len(test.vals))
+		}
+
+		if de.IntHistogram.TotCount != test.expCount {
+			t.Errorf("test %d, got histogram count: %d, expected: %d",
+				i, de.IntHistogram.TotCount, test.expCount)
+		}
+
+		if len(de.Vals) != test.expDistinctVals {
+			t.Errorf("test %d, got vals: %v, expected: %d distinct",
+				i, de.Vals, test.expDistinctVals)
+		}
+
+		if numericKinds[test.kind] &&
+			(de.Min == nil || de.Max == nil || *de.Min != test.expMin || *de.Max != test.expMax) {
+			t.Errorf("test %d, got range: %v..%v, expected: %v..%v",
+				i, de.Min, de.Max, test.expMin, test.expMax)
+		}
+	}
+}
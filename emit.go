@@ -12,6 +12,8 @@ type Emitter struct {
 	emitParts map[string]bool // True when that part should be emitted.
 	emitTypes map[string]bool // True when that value type should be emitted.
 
+	graph bool // True when the emitted VALS feed the run's graphData.
+
 	w io.Writer
 }
 
@@ -36,7 +38,18 @@ func (run *Run) addEmitter(parts, types string, w io.Writer) {
 	})
 }
 
+// addGraphEmitter adds an emitter of the graphable VALS, whose output
+// feeds the run's graphData, like the follow run mode's graph reader.
+func (run *Run) addGraphEmitter(w io.Writer) {
+	run.addEmitter("VALS", graphValTypesCSV(), w)
+	run.emitters[len(run.emitters)-1].graph = true
+}
+
 func (e *Emitter) emitEntryFull(ts, module, level, fnameOut, ol, linesJoined string) {
+	if level == "" { // Keep the columns for parsers like webGraph().
+		level = "-"
+	}
+
 	partKind := ""
 	if len(e.emitParts) > 1 {
 		partKind = "FULL "
@@ -47,9 +60,29 @@ func (e *Emitter) emitEntryFull(ts, module, level, fnameOut, ol, linesJoined str
 	fmt.Fprintln(e.w, linesJoined)
 }
 
+func (e *Emitter) emitEntryEvent(ts, module, level, fnameOut, ol, event string) {
+	if e.emitParts["EVENT"] {
+		if level == "" {
+			level = "-"
+		}
+
+		partKind := ""
+		if len(e.emitParts) > 1 {
+			partKind = "EVENT "
+		}
+
+		fmt.Fprintf(e.w, "  %s %s %s %s %s%s %s\n",
+			ts, level, fnameOut, ol, partKind, module, event)
+	}
+}
+
 func (e *Emitter) emitEntryPart(ts, module, level, fnameOut, ol, partKind string,
 	namePath []string, name, valType, val string, valQuoted bool) {
 	if e.emitParts[partKind] && e.emitTypes[valType] {
+		if level == "" {
+			level = "-"
+		}
+
 		if len(e.emitParts) <= 1 {
 			partKind = ""
 		} else if partKind != "" {
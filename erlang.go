@@ -0,0 +1,615 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// From ns_server logs, where an entry is text around erlang terms, as
+// printed by io_lib's ~p and ~w...
+//   [ns_server:info,2016-04-14T16:10:05.262-07:00,ns_1@127.0.0.1:<0.1.0>:ns_memcached:handle_info:432]
+//     vbucket states: [{vbucket,22,active},{vbucket,23,replica}]
+//   [error_logger:info,2016-04-14T16:10:05.262-07:00,ns_1@127.0.0.1:error_logger<0.6.0>:ale_error_logger_handler:do_log:203]
+//   =========================PROGRESS REPORT=========================
+//             supervisor: {local,ns_server_sup}
+//                started: [{pid,<0.123.0>},
+//                          {name,ns_config_sup},
+//                          {mfargs,{ns_config_sup,start_link,[]}}]
+
+// The kinds of erlTerm.
+const (
+	erlText     = "text"     // A word of the text around the terms.
+	erlTuple    = "tuple"    // Ex: {vbucket,22,active}.
+	erlList     = "list"     // Ex: [1,2,3].
+	erlProplist = "proplist" // A list of {Key, Value} tuples.
+	erlMap      = "map"      // Ex: #{k => v}, where the items alternate keys and values.
+	erlRecord   = "record"   // Ex: #state{k = v}, like a map, where the val is the name.
+	erlAtom     = "atom"     // Ex: active or 'ns_1@127.0.0.1'.
+	erlString   = "string"   // Ex: "default".
+	erlBinary   = "binary"   // Ex: <<"default">> or <<1,2,3>>.
+	erlInt      = "int"      // Ex: -22 or 16#ff, where the val is decimal.
+	erlFloat    = "float"    // Ex: 2.5e-3.
+	erlPid      = "pid"      // Ex: <0.123.0>.
+	erlRef      = "ref"      // Ex: #Ref<0.0.1.2>, #Fun<erl_eval.20.1234> or #Port<0.123>.
+	erlUD       = "ud"       // Ex: <ud>user::1234</ud>.
+
+	// The kinds of the unit-suffixed quantities of the text, where the
+	// val is in base units (see unitVal).
+	erlDuration = "duration" // Ex: 1.5s.
+	erlBytes    = "bytes"    // Ex: 512MB.
+	erlPercent  = "percent"  // Ex: 93%.
+
+	// A word of the text that's a semantic value, like an ADDR.
+	erlSem = "sem" // Ex: 10.0.0.2:11210 or 2016-04-14T16:02:00Z.
+)
+
+// erlTerm is a node of a parsed erlang term, where a scalar has a
+// val, and a tuple, list, proplist, map or record has items.
+type erlTerm struct {
+	kind  string
+	val   string
+	items []*erlTerm
+}
+
+var re_erl_pid = regexp.MustCompile(`^<\d+\.\d+\.\d+>`)
+
+var re_erl_ref = regexp.MustCompile(`^#(Ref|Fun|Port)<[^>]*>`)
+
+var re_erl_number = regexp.MustCompile(`^-?\d+(#[0-9a-zA-Z]+|\.\d+([eE][-+]?\d+)?)?`)
+
+// erlParser is a forgiving parser of the text form of erlang terms,
+// where unexpected characters are skipped instead of being errors,
+// as log entries might be truncated.
+type erlParser struct {
+	s   []byte
+	pos int
+}
+
+// parseErlEntry parses the text of a log entry into a sequence of
+// text words and erlang terms.
+func parseErlEntry(s []byte) []*erlTerm {
+	p := &erlParser{s: s}
+
+	var rv []*erlTerm
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return rv
+		}
+
+		if p.termStart() {
+			start := p.pos
+			if t := p.parseTerm(); t != nil {
+				rv = append(rv, t)
+			}
+			if p.pos == start {
+				p.pos++
+			}
+			continue
+		}
+
+		word := p.parseWord()
+		if t := erlNumber(word); t != nil && len(t.val) > 0 {
+			rv = append(rv, t)
+		} else if t := erlUnit(word); t != nil {
+			rv = append(rv, t)
+		} else if trimmed := strings.TrimRight(word, ",;."); semType(trimmed) != "" {
+			rv = append(rv, &erlTerm{kind: erlSem, val: strings.Trim(trimmed, "'")})
+		} else {
+			rv = append(rv, &erlTerm{kind: erlText, val: word})
+		}
+	}
+}
+
+func (p *erlParser) skipSpace() {
+	for p.pos < len(p.s) && isSpaceByte(p.s[p.pos]) {
+		p.pos++
+	}
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func (p *erlParser) hasPrefix(prefix string) bool {
+	return bytes.HasPrefix(p.s[p.pos:], []byte(prefix))
+}
+
+// termStart returns true when the text at the current position starts
+// an erlang term instead of a word.
+func (p *erlParser) termStart() bool {
+	switch p.s[p.pos] {
+	case '{', '[', '"':
+		return true
+	case '<':
+		return p.hasPrefix("<<") || p.hasPrefix("<ud>") || re_erl_pid.Match(p.s[p.pos:])
+	case '#':
+		return p.pos+1 < len(p.s) && (p.s[p.pos+1] == '{' || isAtomStartByte(p.s[p.pos+1]) ||
+			re_erl_ref.Match(p.s[p.pos:]))
+	}
+	return false
+}
+
+// parseWord returns the next word of text, which is at least one byte.
+func (p *erlParser) parseWord() string {
+	start := p.pos
+
+	for p.pos < len(p.s) && !isSpaceByte(p.s[p.pos]) {
+		if p.pos > start &&
+			(strings.IndexByte("(),;]}", p.s[p.pos]) >= 0 || p.termStart()) {
+			break
+		}
+		p.pos++
+	}
+
+	return string(p.s[start:p.pos])
+}
+
+// parseTerm returns the term at the current position, or nil when
+// there's no term, like at a separator.
+func (p *erlParser) parseTerm() *erlTerm {
+	p.skipSpace()
+	if p.pos >= len(p.s) {
+		return nil
+	}
+
+	c := p.s[p.pos]
+
+	switch {
+	case c == '{':
+		p.pos++
+		return p.parseItems(&erlTerm{kind: erlTuple}, '}')
+
+	case c == '[':
+		p.pos++
+		t := p.parseItems(&erlTerm{kind: erlList}, ']')
+		if isProplist(t.items) {
+			t.kind = erlProplist
+		}
+		return t
+
+	case p.hasPrefix("#{"):
+		p.pos += 2
+		return p.parseItems(&erlTerm{kind: erlMap}, '}')
+
+	case c == '#':
+		if m := re_erl_ref.Find(p.s[p.pos:]); m != nil {
+			p.pos += len(m)
+			return &erlTerm{kind: erlRef, val: string(m)}
+		}
+
+		p.pos++
+		name := p.parseAtom()
+		if name == "" {
+			return nil
+		}
+		p.skipSpace()
+		if p.pos < len(p.s) && p.s[p.pos] == '{' {
+			p.pos++
+			return p.parseItems(&erlTerm{kind: erlRecord, val: name}, '}')
+		}
+		return &erlTerm{kind: erlAtom, val: name}
+
+	case p.hasPrefix("<<"):
+		return p.parseBinary()
+
+	case p.hasPrefix("<ud>"):
+		end := bytes.Index(p.s[p.pos:], []byte("</ud>"))
+		if end < 0 {
+			end = len(p.s) - p.pos
+		} else {
+			end += len("</ud>")
+		}
+		t := &erlTerm{kind: erlUD, val: string(p.s[p.pos : p.pos+end])}
+		p.pos += end
+		return t
+
+	case c == '<':
+		if m := re_erl_pid.Find(p.s[p.pos:]); m != nil {
+			p.pos += len(m)
+			return &erlTerm{kind: erlPid, val: string(m)}
+		}
+		return nil
+
+	case c == '"':
+		return &erlTerm{kind: erlString, val: p.parseQuoted('"')}
+
+	case c == '\'':
+		return &erlTerm{kind: erlAtom, val: p.parseQuoted('\'')}
+
+	case c == '-' || (c >= '0' && c <= '9'):
+		m := re_erl_number.Find(p.s[p.pos:])
+		if m == nil {
+			return nil
+		}
+		p.pos += len(m)
+		return erlNumber(string(m))
+
+	case isAtomStartByte(c):
+		return &erlTerm{kind: erlAtom, val: p.parseAtom()}
+	}
+
+	return nil
+}
+
+// parseItems parses the items of a tuple, list, map or record up to
+// its closing byte, where separators, like ",", "|", "=>" and "=",
+// and truncations, like "...", are skipped.
+func (p *erlParser) parseItems(t *erlTerm, closer byte) *erlTerm {
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return t
+		}
+
+		c := p.s[p.pos]
+		if c == closer {
+			p.pos++
+			return t
+		}
+
+		if c == '}' || c == ']' { // Mismatched, so let the parent close.
+			return t
+		}
+
+		if p.hasPrefix("...") {
+			p.pos += 3
+			continue
+		}
+
+		if p.hasPrefix("=>") || p.hasPrefix(":=") {
+			p.pos += 2
+			continue
+		}
+
+		start := p.pos
+		if item := p.parseTerm(); item != nil {
+			t.items = append(t.items, item)
+		}
+		if p.pos == start { // Skip a separator or an unexpected byte.
+			p.pos++
+		}
+	}
+}
+
+// parseBinary parses a binary, like <<"default">>, <<"abc"...>> or
+// <<1,2,3>>, where a binary of a string has the string as its val.
+func (p *erlParser) parseBinary() *erlTerm {
+	p.pos += 2
+
+	end := bytes.Index(p.s[p.pos:], []byte(">>"))
+	if end < 0 {
+		end = len(p.s) - p.pos
+	}
+
+	content := p.s[p.pos : p.pos+end]
+
+	p.pos += end + 2
+	if p.pos > len(p.s) {
+		p.pos = len(p.s)
+	}
+
+	if len(content) > 0 && content[0] == '"' {
+		sub := &erlParser{s: content}
+		val := sub.parseQuoted('"')
+		if sub.pos < len(content) { // Ex: <<"abc"...>>, which was truncated.
+			val = val + string(content[sub.pos:])
+		}
+		return &erlTerm{kind: erlBinary, val: val}
+	}
+
+	return &erlTerm{kind: erlBinary, val: "<<" + string(content) + ">>"}
+}
+
+// parseQuoted parses a string or a quoted atom, returning its
+// unescaped contents.
+func (p *erlParser) parseQuoted(quote byte) string {
+	start := p.pos
+
+	p.pos++ // Skip the opening quote.
+	for p.pos < len(p.s) && p.s[p.pos] != quote {
+		if p.s[p.pos] == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+
+	if p.pos < len(p.s) {
+		p.pos++ // Skip the closing quote.
+	} else {
+		p.pos = len(p.s)
+	}
+
+	raw := string(p.s[start:p.pos])
+
+	if quote == '"' {
+		if s, err := strconv.Unquote(raw); err == nil {
+			return s
+		}
+	}
+
+	return strings.Trim(raw, string(quote))
+}
+
+// parseAtom parses an unquoted atom, which might also be a variable
+// or a node name, like ns_1@127.0.0.1.
+func (p *erlParser) parseAtom() string {
+	start := p.pos
+
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if !isAtomStartByte(c) && !(c >= '0' && c <= '9') &&
+			c != '@' && c != '.' && c != '$' {
+			break
+		}
+		p.pos++
+	}
+
+	return strings.TrimRight(string(p.s[start:p.pos]), ".")
+}
+
+func isAtomStartByte(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c == '_'
+}
+
+// erlNumber returns an int or float term from a number's text, like
+// "22", "-2.5e-3" or "16#ff", else nil.
+func erlNumber(s string) *erlTerm {
+	if m := re_erl_number.FindString(s); m == "" || m != s {
+		return nil
+	}
+
+	if hash := strings.IndexByte(s, '#'); hash > 0 {
+		base, err := strconv.Atoi(strings.TrimPrefix(s[0:hash], "-"))
+		if err != nil || base < 2 || base > 36 {
+			return nil
+		}
+
+		v, err := strconv.ParseInt(s[hash+1:], base, 64)
+		if err != nil {
+			return nil
+		}
+
+		if strings.HasPrefix(s, "-") {
+			v = -v
+		}
+
+		return &erlTerm{kind: erlInt, val: strconv.FormatInt(v, 10)}
+	}
+
+	if strings.IndexByte(s, '.') >= 0 {
+		return &erlTerm{kind: erlFloat, val: s}
+	}
+
+	return &erlTerm{kind: erlInt, val: s}
+}
+
+// erlUnitKinds maps the value types of unitVal to erlTerm kinds.
+var erlUnitKinds = map[string]string{
+	"DURATION": erlDuration,
+	"BYTES":    erlBytes,
+	"PERCENT":  erlPercent,
+}
+
+// erlUnit returns a term from a word of text that's a unit-suffixed
+// quantity, like "1.5s" or "93%,", else nil.
+func erlUnit(word string) *erlTerm {
+	word = strings.TrimRight(word, ",;.")
+
+	valType, val, n := unitVal([]byte(word))
+	if n <= 0 || n != len(word) {
+		return nil
+	}
+
+	return &erlTerm{kind: erlUnitKinds[valType], val: val}
+}
+
+// isProplist returns true when the items are all {Key, Value} tuples.
+func isProplist(items []*erlTerm) bool {
+	for _, item := range items {
+		if item.kind != erlTuple || len(item.items) != 2 || termName(item.items[0]) == "" {
+			return false
+		}
+	}
+	return len(items) > 0
+}
+
+// termName returns a name for a scalar term, like a proplist's key,
+// or "" when the term can't be a name.
+func termName(t *erlTerm) string {
+	switch t.kind {
+	case erlAtom, erlString, erlBinary, erlInt:
+		if t.val == "" || isUD(t.val) {
+			return ""
+		}
+		return strings.Join(strings.Fields(t.val), "_")
+	}
+	return ""
+}
+
+// ------------------------------------------------------------
+
+// erlEmitter emits the VALS of the erlang terms of a log entry.
+type erlEmitter struct {
+	p *fileProcessor
+
+	startOffset, startLine int64
+
+	ol, ts, module, level string
+}
+
+// processEntryTerms emits the VALS of the erlang terms of an entry,
+// where a term is named by the word before it, like the "stats" of
+// "stats [{curr_items,22}]".
+func (p *fileProcessor) processEntryTerms(startOffset, startLine int64,
+	ol, ts, module, level string, buf []byte) {
+	// Skip the rest of an ns_server entry's header, like
+	// "ns_1@127.0.0.1:<0.1.0>:ns_log:init:42]".
+	if rbrack := bytes.IndexByte(buf, ']'); rbrack >= 0 {
+		if lbrack := bytes.IndexByte(buf, '['); lbrack < 0 || rbrack < lbrack {
+			buf = buf[rbrack+1:]
+		}
+	}
+
+	e := &erlEmitter{p, startOffset, startLine, ol, ts, module, level}
+
+	var name string
+	var text []string
+
+	for _, t := range parseErlEntry(buf) {
+		if t.kind == erlText {
+			name = cleanseName(strings.TrimRight(t.val, ":=,."))
+			text = append(text, t.val)
+			continue
+		}
+
+		if len(text) > 0 {
+			e.emitText("MIDS", text)
+			text = nil
+		}
+
+		e.walk(nil, name, t)
+
+		name = ""
+	}
+
+	e.emitText("ENDS", text)
+}
+
+// walk emits a term with a name under a path, where a compound term's
+// items are under the path and the name.  A {Key, Value} tuple is
+// emitted as Key=Value, and a tagged tuple, like {vbucket,22,active},
+// has a path of its tag and id, like "[vbucket 22]", where the rest
+// of its elements are named by their erlang element position.
+func (e *erlEmitter) walk(path []string, name string, t *erlTerm) {
+	switch t.kind {
+	case erlTuple:
+		sub := pathAppend(path, name)
+
+		if len(t.items) > 0 {
+			key := termName(t.items[0])
+
+			if key != "" && len(t.items) == 2 {
+				e.walk(sub, key, t.items[1])
+				return
+			}
+
+			if key != "" && t.items[0].kind != erlInt {
+				sub = pathAppend(sub, key)
+
+				first := 1
+				if len(t.items) > 1 {
+					if id := termName(t.items[1]); id != "" {
+						sub = pathAppend(sub, id)
+						first = 2
+					}
+				}
+
+				for i := first; i < len(t.items); i++ {
+					e.walk(sub, strconv.Itoa(i+1), t.items[i])
+				}
+				return
+			}
+		}
+
+		for _, item := range t.items {
+			e.walk(sub, "", item)
+		}
+
+	case erlList, erlProplist:
+		sub := pathAppend(path, name)
+
+		for _, item := range t.items {
+			e.walk(sub, "", item)
+		}
+
+	case erlMap, erlRecord:
+		sub := pathAppend(pathAppend(path, name), t.val)
+
+		for i := 0; i+1 < len(t.items); i += 2 {
+			e.walk(sub, termName(t.items[i]), t.items[i+1])
+		}
+
+	default:
+		if name != "" {
+			e.emit(path, name, t)
+		}
+	}
+}
+
+// emit emits a scalar term as a name=value pair, where an int is an
+// INT, a float is a FLOAT, true and false are BOOL, user data is UD,
+// quantities are DURATION, BYTES or PERCENT, a pid is a PID, nodes,
+// addresses, uuids and timestamps have their semantic types, like
+// NODE, and the rest are STRING.
+func (e *erlEmitter) emit(path []string, name string, t *erlTerm) {
+	valType, val := "STRING", strconv.Quote(t.val)
+
+	switch t.kind {
+	case erlInt:
+		valType, val = "INT", t.val
+	case erlFloat:
+		valType, val = "FLOAT", t.val
+	case erlPid:
+		valType = "PID"
+	case erlSem:
+		valType = semType(t.val)
+	case erlAtom:
+		if t.val == "true" || t.val == "false" {
+			valType, val = "BOOL", t.val
+		} else if semType(t.val) != "" {
+			valType = semType(t.val)
+		}
+	case erlDuration:
+		valType, val = "DURATION", t.val
+	case erlBytes:
+		valType, val = "BYTES", t.val
+	case erlPercent:
+		valType, val = "PERCENT", t.val
+	case erlUD:
+		valType = "UD"
+	case erlString, erlBinary:
+		if isUD(t.val) {
+			valType = "UD"
+		} else if semType(t.val) != "" {
+			valType = semType(t.val)
+		}
+	}
+
+	e.p.emitValType(e.startOffset, e.startLine, e.ol, e.ts, e.module, e.level,
+		path, name, valType, val)
+}
+
+func (e *erlEmitter) emitText(partKind string, text []string) {
+	e.p.run.emitEntryPart(e.ts, e.module, e.level, e.p.dirBase,
+		e.p.fname, e.p.fnameBase, e.p.fnameOut,
+		e.ol, e.startOffset, e.startLine,
+		partKind, nil, "", "STRING", strings.Join(text, " "), true)
+}
+
+// pathAppend returns a copy of a path with a part appended, unless
+// the part is "".
+func pathAppend(path []string, part string) []string {
+	if part == "" {
+		return path
+	}
+
+	rv := make([]string, len(path), len(path)+1)
+	copy(rv, path)
+
+	return append(rv, part)
+}
@@ -0,0 +1,134 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// termString returns a compact form of a term for comparisons, like
+// `tuple{atom:vbucket int:22}`.
+func termString(t *erlTerm) string {
+	if t.items == nil && t.kind != erlTuple && t.kind != erlList &&
+		t.kind != erlMap && t.kind != erlRecord {
+		return t.kind + ":" + t.val
+	}
+
+	var items []string
+	for _, item := range t.items {
+		items = append(items, termString(item))
+	}
+
+	return t.kind + t.val + "{" + strings.Join(items, " ") + "}"
+}
+
+func TestParseErlEntry(t *testing.T) {
+	tests := []struct {
+		entry string
+		exp   []string
+	}{
+		{"state {ok} now",
+			[]string{"text:state", "tuple{atom:ok}", "text:now"}},
+		{"{}",
+			[]string{"tuple{}"}},
+		{"vbucket states: [{vbucket,22,active}]",
+			[]string{"text:vbucket", "text:states:",
+				"list{tuple{atom:vbucket int:22 atom:active}}"}},
+		{"stats [{curr_items,22},{mem_used,1.5}]",
+			[]string{"text:stats",
+				"proplist{tuple{atom:curr_items int:22} tuple{atom:mem_used float:1.5}}"}},
+		{`#state{bucket = <<"default">>, count = 16#ff}`,
+			[]string{"recordstate{atom:bucket binary:default atom:count int:255}"}},
+		{`m #{k => 1, "s" => true}`,
+			[]string{"text:m", "map{atom:k int:1 string:s atom:true}"}},
+		{`<<"beer-sample">> <<1,2,3>> <<"abc"...>>`,
+			[]string{"binary:beer-sample", "binary:<<1,2,3>>", "binary:abc..."}},
+		{"pid <0.123.0> ref #Ref<0.0.1.2> node 'ns_1@10.0.0.2'",
+			[]string{"text:pid", "pid:<0.123.0>", "text:ref", "ref:#Ref<0.0.1.2>",
+				"text:node", "sem:ns_1@10.0.0.2"}},
+		{"took 1.5s used 93%",
+			[]string{"text:took", "duration:1500000000", "text:used", "percent:0.93"}},
+		// Truncated terms are closed at the end of the entry.
+		{"stats [{curr_items,22},{mem_used",
+			[]string{"text:stats", "list{tuple{atom:curr_items int:22} tuple{atom:mem_used}}"}},
+		{`x {a,<<"abc`,
+			[]string{"text:x", "tuple{atom:a binary:abc}"}},
+		{"x [1,2,...]",
+			[]string{"text:x", "list{int:1 int:2}"}},
+		{"x {a,b]}",
+			[]string{"text:x", "tuple{atom:a atom:b}", "text:]", "text:}"}},
+	}
+
+	for i, test := range tests {
+		var got []string
+		for _, term := range parseErlEntry([]byte(test.entry)) {
+			got = append(got, termString(term))
+		}
+
+		if !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("test %d, entry: %q, got: %q, expected: %q",
+				i, test.entry, got, test.exp)
+		}
+	}
+}
+
+func TestErlWalk(t *testing.T) {
+	tests := []struct {
+		entry string
+		exp   []string
+	}{
+		// One-element tagged tuples have no id to be emitted.
+		{"state {ok} now", nil},
+		{"y {shutdown}", nil},
+		{"y {shutdown} count 3", []string{"[] count = INT 3"}},
+		{"x {{ok}}", nil},
+		{"vbucket states: [{vbucket,22,active},{vbucket,23,replica}]",
+			[]string{`[states vbucket 22] 3 = STRING "active"`,
+				`[states vbucket 23] 3 = STRING "replica"`}},
+		{"x {node,'ns_1@10.0.0.2',[{port,11210}]}",
+			[]string{"[x node ns_1@10.0.0.2 3] port = INT 11210"}},
+		{"result {ok,22}",
+			[]string{"[result] ok = INT 22"}},
+		{`state #state{bucket = <<"default">>, count = 3, pid = <0.12.0>}`,
+			[]string{`[state state] bucket = STRING "default"`,
+				"[state state] count = INT 3",
+				`[state state] pid = PID "<0.12.0>"`}},
+		{`m #{k => 1, "s" => true}`,
+			[]string{"[m] k = INT 1", "[m] s = BOOL true"}},
+		{"bucket <<\"beer-sample\">> bin <<1,2,3>>",
+			[]string{`[] bucket = STRING "beer-sample"`, `[] bin = STRING "<<1,2,3>>"`}},
+		{"owner <0.123.0> at 10.0.0.2:11210",
+			[]string{`[] owner = PID "<0.123.0>"`, `[] at = ADDR "10.0.0.2:11210"`}},
+		{"stats [{curr_items,22},{mem_used,1",
+			[]string{"[stats] curr_items = INT 22", "[stats] mem_used = INT 1"}},
+		{"stats [{curr_items,22},{mem_used", []string{"[stats] curr_items = INT 22"}},
+	}
+
+	for i, test := range tests {
+		content := "h1\nh2\nh3\nh4\n[ns_server:info,2016-04-14T16:10:05.262-07:00," +
+			"ns_1@127.0.0.1:<0.1.0>:ns_log:init:42]" + test.entry + "\n"
+
+		p, buf := testFileProcessor("ns_server.info.log", FileMetaNS, content, "VALS",
+			"INT,FLOAT,BOOL,STRING,UD,DURATION,BYTES,PERCENT,ADDR,NODE,UUID,PID,TS")
+
+		if err := p.process(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := emittedVals(buf); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("test %d, entry: %q, got: %q, expected: %q",
+				i, test.entry, got, test.exp)
+		}
+	}
+}
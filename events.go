@@ -0,0 +1,125 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// From master_events.log, which has the cluster's rebalance, vbucket
+// move and failover events, as a JSON object per line...
+//   {"ts":1462516999.912837,"type":"rebalanceStart","pid":"<0.3211.0>",
+//    "nodesBefore":["ns_1@10.0.0.1"],"nodesAfter":["ns_1@10.0.0.1","ns_1@10.0.0.2"]}
+//   {"ts":1462517000.123456,"type":"vbucketMoveStart","pid":"<0.3322.0>",
+//    "bucket":"default","node":"ns_1@10.0.0.1","vbucket":1023,
+//    "chainBefore":["ns_1@10.0.0.1","undefined"],"chainAfter":["ns_1@10.0.0.2","ns_1@10.0.0.1"]}
+
+// processMasterEventsEntry processes a master_events.log line, which
+// is emitted as FULL, as an EVENT, and with its fields as VALS that
+// have a path of the event type, like "[vbucketMoveStart]".
+func processMasterEventsEntry(p *fileProcessor, startOffset, startLine int64, lines []string) {
+	line := strings.TrimSpace(lines[0])
+	if !strings.HasPrefix(line, "{") { // Ex: a cbcollect-info header line.
+		return
+	}
+
+	var event map[string]interface{}
+
+	err := json.Unmarshal([]byte(line), &event)
+	if err != nil {
+		return
+	}
+
+	ts := jsonTS(event, p.collectTS)
+
+	eventType, _ := event["type"].(string)
+
+	module, ol := emitCommonPrep("", p.fnameBase, startOffset, startLine)
+
+	p.run.emitEntryFull(ts, module, "", p.dirBase,
+		p.fname, p.fnameBase, p.fnameOut, ol, startOffset, startLine, lines)
+
+	var vals []string // The event's summary, like "vbucket=1023".
+
+	var emitFields func(namePath []string, fields map[string]interface{})
+
+	emitFields = func(namePath []string, fields map[string]interface{}) {
+		for _, name := range sortedKeys(fields) {
+			if len(namePath) <= 1 && (name == "ts" || name == "pid") {
+				continue
+			}
+
+			if v, ok := fields[name].(map[string]interface{}); ok {
+				emitFields(append(namePath, name), v)
+				continue
+			}
+
+			val, ok := jsonValString(fields[name])
+			if !ok {
+				continue
+			}
+
+			if len(namePath) <= 1 && name != "type" {
+				vals = append(vals, name+"="+val)
+			}
+
+			p.emitVal(startOffset, startLine, ol, ts, module, "", namePath, name, val)
+		}
+	}
+
+	emitFields([]string{eventType}, event)
+
+	p.run.emitEntryEvent(ts, module, "", p.dirBase,
+		p.fname, p.fnameBase, p.fnameOut, ol, startOffset, startLine,
+		strings.TrimSpace(eventType+" "+strings.Join(vals, " ")))
+}
+
+// jsonValString returns a decoded JSON value as a string, where a
+// list, like a list of nodes, is joined by commas.  It returns false
+// for an object or null.
+func jsonValString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case []interface{}:
+		var parts []string
+		for _, x := range v {
+			if s, ok := x.(string); ok {
+				parts = append(parts, s)
+			} else {
+				b, _ := json.Marshal(x)
+				parts = append(parts, string(b))
+			}
+		}
+		return strings.Join(parts, ","), true
+	}
+
+	return "", false
+}
+
+// jsonTS returns the timestamp from a JSON "ts" field of seconds
+// since the epoch, like 1462516999.912837, else the default.
+func jsonTS(fields map[string]interface{}, tsDefault string) string {
+	if secs, ok := fields["ts"].(float64); ok {
+		return time.Unix(0, int64(secs*float64(time.Second))).
+			UTC().Format(TSLayout)
+	}
+
+	return tsDefault
+}
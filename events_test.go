@@ -0,0 +1,84 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONTS(t *testing.T) {
+	tests := []struct {
+		fields map[string]interface{}
+		exp    string
+	}{
+		{map[string]interface{}{"ts": 1462516999.912837}, "2016-05-06T06:43:19.912Z"},
+		{map[string]interface{}{"ts": float64(1462516999)}, "2016-05-06T06:43:19.000Z"},
+		{map[string]interface{}{"ts": "1462516999"}, "default"},
+		{map[string]interface{}{}, "default"},
+	}
+
+	for i, test := range tests {
+		if got := jsonTS(test.fields, "default"); got != test.exp {
+			t.Errorf("test %d, got: %s, expected: %s", i, got, test.exp)
+		}
+	}
+}
+
+func TestProcessMasterEventsEntry(t *testing.T) {
+	content := strings.Join([]string{
+		"master_events.log header",
+		`{"ts":1462517000.123456,"type":"vbucketMoveStart","pid":"<0.3322.0>",` +
+			`"bucket":"default","node":"ns_1@10.0.0.1","vbucket":1023,` +
+			`"chainBefore":["ns_1@10.0.0.1","undefined"],"chainAfter":["ns_1@10.0.0.2","ns_1@10.0.0.1"],` +
+			`"stats":{"moved":true}}`,
+		`{"type":"rebalanceEnd"}`,
+		`{"ts":1462517001, "type":`,
+	}, "\n") + "\n"
+
+	p, buf := testFileProcessor("master_events.log", FileMetas["master_events.log"], content,
+		"FULL,VALS,EVENT", "INT,BOOL,STRING,NODE")
+	p.collectTS = "2016-05-06T06:26:39.000Z"
+
+	if err := p.process(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		fields := strings.Fields(line)
+		got = append(got, strings.Join(append(fields[0:1], fields[3:]...), " "))
+	}
+
+	exp := []string{
+		"2016-05-06T06:43:20.123Z 25:2 FULL " + strings.Split(content, "\n")[1],
+		`2016-05-06T06:43:20.123Z 25:2 VALS [vbucketMoveStart] bucket = STRING "default"`,
+		`2016-05-06T06:43:20.123Z 25:2 VALS [vbucketMoveStart] chainAfter = STRING "ns_1@10.0.0.2,ns_1@10.0.0.1"`,
+		`2016-05-06T06:43:20.123Z 25:2 VALS [vbucketMoveStart] chainBefore = STRING "ns_1@10.0.0.1,undefined"`,
+		`2016-05-06T06:43:20.123Z 25:2 VALS [vbucketMoveStart] node = NODE "ns_1@10.0.0.1"`,
+		`2016-05-06T06:43:20.123Z 25:2 VALS [vbucketMoveStart stats] moved = BOOL true`,
+		`2016-05-06T06:43:20.123Z 25:2 VALS [vbucketMoveStart] type = STRING "vbucketMoveStart"`,
+		`2016-05-06T06:43:20.123Z 25:2 VALS [vbucketMoveStart] vbucket = INT 1023`,
+		"2016-05-06T06:43:20.123Z 25:2 EVENT vbucketMoveStart bucket=default" +
+			" chainAfter=ns_1@10.0.0.2,ns_1@10.0.0.1 chainBefore=ns_1@10.0.0.1,undefined" +
+			" node=ns_1@10.0.0.1 vbucket=1023",
+		// Without a ts, an event has the collection's time.
+		`2016-05-06T06:26:39.000Z 266:3 FULL {"type":"rebalanceEnd"}`,
+		`2016-05-06T06:26:39.000Z 266:3 VALS [rebalanceEnd] type = STRING "rebalanceEnd"`,
+		"2016-05-06T06:26:39.000Z 266:3 EVENT rebalanceEnd",
+	}
+
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got: %q, expected: %q", got, exp)
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"go/scanner"
@@ -25,12 +27,31 @@ type fileProcessor struct {
 	run       *Run
 	dir       string
 	dirBase   string
-	fname     string
+	fname     string // The name of the segment of the current entry.
 	fnameBase string // Ex: fname of "ns_server.fts.log" has fnameBase of "fts".
 	fnameOut  string // Space right padded "dirBase/fname", ready for logging.
 	fmeta     FileMeta
+	segments  []*inputFile // Rotated files, in chronological order.
+	tsInfer   *tsInfer     // For entries without a year or zone, like syslog's.
+	collectTS string       // For entries without a timestamp.
+	lastTS    string       // The timestamp of the previous entry, see FileMeta.InheritTS.
 	dict      Dict
 	buf       []byte // Reusable buf to reduce garbage.
+
+	// The text that's being tokenized, for looking around a token.
+	tokFile *token.File
+	tokBuf  []byte
+
+	// Optional state for FileMeta.ProcessEntry, like the current
+	// section of a sectioned file.
+	state map[string]string
+
+	// The current entry, which is assembled from consecutive lines.
+	entrySegment     *inputFile
+	entryStartOffset int64
+	entryStartLine   int64
+	entryLines       []string
+	entryBytes       int // Includes the bytes of skipped lines.
 }
 
 // A tokLit associates a token and a literal string.
@@ -38,21 +59,44 @@ type tokLit struct {
 	tok     token.Token
 	lit     string
 	emitted bool // Marked true when this tokLit has been emitted.
+
+	// Optional, the value type of a quantity that spans tokens, like
+	// the DURATION of "1.5s", where the lit is in base units.
+	valType string
 }
 
 // ------------------------------------------------------------
 
 func (p *fileProcessor) process() error {
+	// The segments of rotated files are processed as a single stream,
+	// so an entry might continue from one segment into the next.
+	for _, segment := range p.segments {
+		err := p.processSegment(segment)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.processEntryCurr()
+
+	return nil
+}
+
+func (p *fileProcessor) processSegment(segment *inputFile) error {
 	if p.run.ProgressEvery <= 0 {
-		fmt.Fprintf(os.Stderr, "processing %s/%s\n", p.dirBase, p.fname)
+		fmt.Fprintf(os.Stderr, "processing %s/%s\n", p.dirBase, segment.name)
 	}
 
-	f, err := os.Open(p.dir + string(os.PathSeparator) + p.fname)
+	f, err := segment.open()
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
+	if p.fmeta.ProcessFile != nil {
+		return p.fmeta.ProcessFile(p, segment, f)
+	}
+
 	// Repeatably scan until we have the consecutive lines to make up
 	// an "entry", and invoke processEntry() on every entry.
 	scanner := bufio.NewScanner(f)
@@ -61,41 +105,88 @@ func (p *fileProcessor) process() error {
 	var currOffset int64
 	var currLine int64
 
-	var entryStartOffset int64
-	var entryStartLine int64
-	var entryLines []string
-
 	for scanner.Scan() {
 		lineStr := scanner.Text()
 
 		currLine++
-		if currLine <= int64(p.fmeta.HeaderSize) { // Skip header.
-			currOffset += int64(len(lineStr) + 1)
-			continue
-		}
 
-		if p.fmeta.EntryStart == nil || p.fmeta.EntryStart(lineStr) {
-			p.processEntry(entryStartOffset, entryStartLine, entryLines)
+		p.processLine(segment, lineStr, currOffset, currLine)
+
+		currOffset += int64(len(lineStr) + 1)
+	}
+
+	return scanner.Err()
+}
+
+// processLine appends a line to the current entry, unless the line
+// starts a new entry, in which case the current entry is complete and
+// is processed first.
+func (p *fileProcessor) processLine(segment *inputFile, lineStr string,
+	offset, line int64) {
+	if line <= int64(p.fmeta.HeaderSize) { // Skip header.
+		return
+	}
+
+	if p.fmeta.entryStart(lineStr) {
+		p.processEntryCurr()
 
-			entryStartOffset = currOffset
-			entryStartLine = currLine
-			entryLines = entryLines[0:0]
+		p.entrySegment = segment
+		p.entryStartOffset = offset
+		p.entryStartLine = line
+		p.entryLines = p.entryLines[0:0]
+		p.entryBytes = 0
+	}
+
+	// Only the start of a giant entry is kept, to bound memory usage,
+	// except for a ProcessEntry hook, like for ddocs.log's JSON or
+	// couchbase.log's command outputs, which needs the whole entry.
+	if p.run.MaxEntryBytes > 0 && p.fmeta.ProcessEntry == nil && len(p.entryLines) > 0 &&
+		p.entryBytes+len(lineStr) > p.run.MaxEntryBytes {
+		if p.entryBytes <= p.run.MaxEntryBytes {
+			p.entryLines = append(p.entryLines, "...")
 		}
+		p.entryBytes += len(lineStr) + 1
+		return
+	}
 
-		entryLines = append(entryLines, lineStr)
-		currOffset += int64(len(lineStr) + 1)
+	p.entryLines = append(p.entryLines, lineStr)
+	p.entryBytes += len(lineStr) + 1
+}
+
+// entryTruncated returns true when the current entry was cut short
+// by -maxEntryBytes.
+func (p *fileProcessor) entryTruncated() bool {
+	return p.run.MaxEntryBytes > 0 && p.entryBytes > p.run.MaxEntryBytes
+}
+
+// warnTruncated reports to stderr when the current entry was cut
+// short by -maxEntryBytes and part of it, like a JSON object, then
+// couldn't be parsed.
+func (p *fileProcessor) warnTruncated(startLine int64, what string) {
+	if p.entryTruncated() {
+		fmt.Fprintf(os.Stderr, "warning: %s/%s:%d, %s in entry truncated by -maxEntryBytes\n",
+			p.dirBase, p.fname, startLine, what)
 	}
+}
 
-	p.processEntry(entryStartOffset, entryStartLine, entryLines)
+func (p *fileProcessor) processEntryCurr() {
+	p.processEntry(p.entrySegment, p.entryStartOffset, p.entryStartLine, p.entryLines)
 
-	return scanner.Err()
+	p.entrySegment = nil
 }
 
-func (p *fileProcessor) processEntry(startOffset, startLine int64, lines []string) {
-	if startLine <= 0 || len(lines) <= 0 {
+func (p *fileProcessor) processEntry(segment *inputFile,
+	startOffset, startLine int64, lines []string) {
+	if segment == nil || startLine <= 0 || len(lines) <= 0 {
 		return
 	}
 
+	p.useSegment(segment)
+
+	if p.run.redactor != nil {
+		lines = p.run.redactor.redactLines(lines)
+	}
+
 	if p.run.EmitOrig != "" {
 		linesJoined := strings.Join(lines, "\n")
 		if p.run.EmitOrig == "single" {
@@ -107,28 +198,30 @@ func (p *fileProcessor) processEntry(startOffset, startLine int64, lines []strin
 		p.run.m.Unlock()
 	}
 
+	if p.fmeta.ProcessEntry != nil {
+		p.fmeta.ProcessEntry(p, startOffset, startLine, lines)
+		return
+	}
+
 	firstLine := lines[0]
 
-	matchIndex := p.fmeta.EntryRE.FindStringSubmatchIndex(firstLine)
+	entryRE, matchIndex := p.fmeta.entryMatch(firstLine)
 	if len(matchIndex) <= 0 {
 		return
 	}
 
-	ts := string(p.fmeta.EntryRE.ExpandString(nil,
-		"${year}-${month}-${day}T${HH}:${MM}:${SS}.${SSSS}", firstLine, matchIndex))
-	if len(ts) > len("2016-04-19T23:10:31.209") {
-		ts = ts[0:len("2016-04-19T23:10:31.209")]
-	}
+	ts := p.entryTS(entryRE, firstLine, matchIndex)
 
-	module := string(p.fmeta.EntryRE.ExpandString(nil, "${module}", firstLine, matchIndex))
+	module := string(entryRE.ExpandString(nil, "${module}", firstLine, matchIndex))
 
-	level := string(p.fmeta.EntryRE.ExpandString(nil, "${level}", firstLine, matchIndex))
+	level := string(entryRE.ExpandString(nil, "${level}", firstLine, matchIndex))
 	level = strings.ToUpper(strings.Trim(level, "[]"))
 	if len(level) > 4 && level != "DEBUG" {
 		level = level[0:4]
 	}
 
-	lines[0] = firstLine[matchIndex[1]:] // Strip off EntryRE's match.
+	// Strip off entryRE's match, which is usually at the line's start.
+	lines[0] = firstLine[0:matchIndex[0]] + firstLine[matchIndex[1]:]
 
 	var ol string // The ol looks like "offset:line".
 
@@ -137,27 +230,185 @@ func (p *fileProcessor) processEntry(startOffset, startLine int64, lines []strin
 	p.run.emitEntryFull(ts, module, level, p.dirBase,
 		p.fname, p.fnameBase, p.fnameOut, ol, startOffset, startLine, lines)
 
+	if p.fmeta.ValsRE != nil {
+		valsMatch := p.fmeta.ValsRE.FindStringSubmatch(firstLine)
+		if valsMatch != nil {
+			p.emitValsMatch(startOffset, startLine, ol, ts, module, level, valsMatch)
+			return
+		}
+	}
+
 	p.buf = p.buf[0:0]
 	for _, line := range lines {
 		p.buf = append(p.buf, []byte(line)...)
 		p.buf = append(p.buf, '\n')
 	}
 
+	// Tokenizing a giant entry, like a config dump, is slow and its
+	// VALS are mostly noise, so it's only emitted as FULL.
+	if p.run.MaxTokenizeBytes > 0 && len(p.buf) > p.run.MaxTokenizeBytes {
+		return
+	}
+
+	if p.fmeta.ErlangTerms {
+		if p.fmeta.Cleanser != nil {
+			p.buf = p.fmeta.Cleanser(p.buf)
+		}
+
+		p.processEntryTerms(startOffset, startLine, ol, ts, module, level, p.buf)
+		return
+	}
+
+	p.processEntryJSON(startOffset, startLine, ol, ts, module, level, p.buf)
+}
+
+// tokenizeText emits the VALS of text that isn't JSON, using go's
+// tokenizer and heuristics for names and paths.
+func (p *fileProcessor) tokenizeText(startOffset, startLine int64,
+	ol, ts, module, level string, buf []byte) {
 	if p.fmeta.Cleanser != nil {
-		p.buf = p.fmeta.Cleanser(p.buf)
+		buf = p.fmeta.Cleanser(buf)
 	}
 
+	buf = semStringify(udStringify(buf))
+
 	var s scanner.Scanner // Use go's tokenizer to parse the entry.
 
 	fset := token.NewFileSet()
 
-	s.Init(fset.AddFile(p.dir+string(os.PathSeparator)+p.fname,
-		fset.Base(), len(p.buf)), p.buf, nil /* No error handler. */, 0)
+	p.tokFile = fset.AddFile(p.dir+string(os.PathSeparator)+p.fname,
+		fset.Base(), len(buf))
+	p.tokBuf = buf
+
+	s.Init(p.tokFile, buf, nil /* No error handler. */, 0)
 
 	p.processEntryTokens(startOffset, startLine, ol, ts, module, level, &s,
 		make([]string, 0, 20))
 }
 
+// useSegment switches the fname to that of a segment, as the emitted
+// offsets are relative to the segment.
+func (p *fileProcessor) useSegment(segment *inputFile) {
+	if p.fname != segment.name {
+		p.fname = segment.name
+		p.fnameOut = p.run.fnameOut(p.dirBase, segment.name)
+	}
+}
+
+// emitValsMatch emits the named groups of a ValsRE match as VALS,
+// with any ValsUnits unit, where the value type is inferred as usual.
+func (p *fileProcessor) emitValsMatch(startOffset, startLine int64,
+	ol, ts, module, level string, valsMatch []string) {
+	for i, name := range p.fmeta.ValsRE.SubexpNames() {
+		val := valsMatch[i]
+		if name == "" || val == "" || val == "-" {
+			continue
+		}
+
+		if unit := p.fmeta.ValsUnits[name]; unit != "" && re_int_only.MatchString(val) {
+			val += unit
+		}
+
+		p.emitVal(startOffset, startLine, ol, ts, module, level, nil, name, val)
+	}
+}
+
+// emitVal emits a name=value pair as VALS, where the value type is
+// inferred from the value (see valTypeOf), and where a quantity with
+// a unit, like "1.5s", is emitted in base units (see unitVal).
+func (p *fileProcessor) emitVal(startOffset, startLine int64,
+	ol, ts, module, level string, namePath []string, name, val string) {
+	valType := valTypeOf(val)
+	if valType == "STRING" {
+		if unitType, unitVal, n := unitVal([]byte(val)); n == len(val) {
+			valType, val = unitType, unitVal
+		}
+	}
+
+	if valType == "STRING" || valType == "UD" || semTypes[valType] {
+		val = strconv.Quote(val)
+	}
+
+	p.emitValType(startOffset, startLine, ol, ts, module, level, namePath, name, valType, val)
+}
+
+// valTypeOf returns the value type of an unquoted value, which is
+// INT or FLOAT when it's a number, like "-5" or "0.75", BOOL when it's
+// "true" or "false", UD when it's a user data tag, else STRING.
+func valTypeOf(val string) string {
+	switch {
+	case re_signed_int_only.MatchString(val):
+		return "INT"
+	case re_float_only.MatchString(val):
+		return "FLOAT"
+	case val == "true" || val == "false":
+		return "BOOL"
+	case isUD(val):
+		return "UD"
+	}
+	if valType := semType(val); valType != "" {
+		return valType
+	}
+	return "STRING"
+}
+
+// emitValType emits a name=value pair of a value type as VALS.
+func (p *fileProcessor) emitValType(startOffset, startLine int64,
+	ol, ts, module, level string, namePath []string, name, valType, val string) {
+	p.dict.AddDictEntry(valType, name, val)
+	p.run.emitEntryPart(ts, module, level, p.dirBase,
+		p.fname, p.fnameBase, p.fnameOut,
+		ol, startOffset, startLine,
+		"VALS", namePath, name, valType, val, false)
+}
+
+// entryTS returns the timestamp of an entry from the entryRE match,
+// formatted like "2016-04-19T23:10:31.209-07:00", or the collection
+// timestamp when the entry has no time.
+func (p *fileProcessor) entryTS(entryRE *regexp.Regexp,
+	line string, matchIndex []int) string {
+	group := func(name string) string {
+		return string(entryRE.ExpandString(nil, "${"+name+"}", line, matchIndex))
+	}
+
+	if group("HH") == "" { // Ex: a diag.log section, which has no time.
+		if p.fmeta.InheritTS && p.lastTS != "" {
+			return p.lastTS
+		}
+		return p.collectTS
+	}
+
+	month := group("month")
+	if monthNum, exists := monthNums[month]; exists {
+		month = monthNum
+	}
+
+	day := group("day")
+	if len(day) < 2 {
+		day = "0" + day
+	}
+
+	year := group("year")
+	if year == "" {
+		year = p.tsInfer.year(month, day)
+	}
+
+	// The time zone offset is kept, like "-07:00", where an entry
+	// without an offset is in the node's local time.
+	tz := group("tz")
+	if tz == "" {
+		tz = p.tsInfer.zone
+	} else {
+		tz = normalizeTZ(tz)
+	}
+
+	p.lastTS = year + "-" + month + "-" + day +
+		"T" + group("HH") + ":" + group("MM") + ":" + group("SS") +
+		"." + (group("SSSS") + "000")[0:3] + tz
+
+	return p.lastTS
+}
+
 // levelDelta tells us how some tokens affect our "depth" of nesting.
 var levelDelta = map[token.Token]int{
 	token.LPAREN: 1,
@@ -194,17 +445,46 @@ func (p *fileProcessor) processEntryTokens(startOffset, startLine int64,
 	ol, ts, module, level string, s *scanner.Scanner, path []string) {
 	var tokLits []tokLit
 	var emitted int
+	var negative bool
+	var skipUntil int // Offset of the end of a unit-suffixed quantity.
 
 	for {
-		_, tok, lit := s.Scan()
+		pos, tok, lit := s.Scan()
 		if tok == token.EOF {
 			break
 		}
 
-		if skipToken[tok] {
+		if skipToken[tok] || p.tokFile.Offset(pos) < skipUntil {
 			continue
 		}
 
+		// Keep the sign of a negative number, like "-5", as go's
+		// tokenizer splits it into SUB and INT tokens.
+		if tok == token.SUB && p.negativeSign(pos) {
+			negative = true
+			continue
+		}
+
+		numStart := p.tokFile.Offset(pos)
+
+		if negative {
+			if tok == token.INT || tok == token.FLOAT {
+				lit = "-" + lit
+				numStart--
+			}
+			negative = false
+		}
+
+		// A quantity with a unit, like "1.5s", "512MB" or "93%", is a
+		// single value in base units, like nanoseconds.
+		if tok == token.INT || tok == token.FLOAT {
+			if valType, val, n := unitVal(p.tokBuf[numStart:]); n > 0 {
+				tokLits = append(tokLits, tokLit{tok: tok, lit: val, valType: valType})
+				skipUntil = numStart + n
+				continue
+			}
+		}
+
 		delta, deltaExists := levelDelta[tok]
 		if delta > 0 {
 			pathSub := path
@@ -223,10 +503,10 @@ func (p *fileProcessor) processEntryTokens(startOffset, startLine int64,
 		} else {
 			// If the token is merge'able with the previous token,
 			// then merge.  For example, we can merge an IDENT that's
-			// followed by a consecutive IDENT.
-			if !deltaExists && len(tokLits) > 0 {
+			// followed by a consecutive IDENT, but not a BOOL.
+			if !deltaExists && len(tokLits) > 0 && tokenType(tok, lit) != "BOOL" {
 				tokLitPrev := tokLits[len(tokLits)-1]
-				if !tokLitPrev.emitted {
+				if !tokLitPrev.emitted && tokenType(tokLitPrev.tok, tokLitPrev.lit) != "BOOL" {
 					_, prevDeltaExists := levelDelta[tokLitPrev.tok]
 					if !prevDeltaExists {
 						tokLits[len(tokLits)-1].lit =
@@ -238,13 +518,23 @@ func (p *fileProcessor) processEntryTokens(startOffset, startLine int64,
 				}
 			}
 
-			tokLits = append(tokLits, tokLit{tok, lit, false})
+			tokLits = append(tokLits, tokLit{tok: tok, lit: lit})
 		}
 	}
 
 	p.emitTokLits(startOffset, startLine, ol, ts, module, level, path, tokLits, emitted)
 }
 
+// negativeSign returns true when the "-" at pos is the sign of a
+// number, like "=-5" or " -0.5", rather than a hyphen, like in a date.
+func (p *fileProcessor) negativeSign(pos token.Pos) bool {
+	offset := p.tokFile.Offset(pos)
+
+	return offset+1 < len(p.tokBuf) &&
+		p.tokBuf[offset+1] >= '0' && p.tokBuf[offset+1] <= '9' &&
+		(offset <= 0 || strings.IndexByte(" \t\n=:([{,", p.tokBuf[offset-1]) >= 0)
+}
+
 // emitTokLits invokes run.emitEntryPart() on the tokens that haven't been
 // emitted yet, along with heuristic preprocessing & cleanup, too.
 func (p *fileProcessor) emitTokLits(startOffset, startLine int64,
@@ -258,7 +548,10 @@ func (p *fileProcessor) emitTokLits(startOffset, startLine int64,
 		}
 		tokLit.emitted = true
 
-		tokStr := tokLit.tok.String()
+		tokStr := tokLit.valType
+		if tokStr == "" {
+			tokStr = tokenType(tokLit.tok, tokLit.lit)
+		}
 
 		strs := strings.Trim(strings.Join(s, " "), "\t\n .:,")
 		p.run.emitEntryPart(ts, module, level, p.dirBase,
@@ -295,11 +588,13 @@ func (p *fileProcessor) emitTokLits(startOffset, startLine int64,
 }
 
 // nameFromTokLits returns the last IDENT or STRING from the tokLits,
-// which the caller can use as a name.
+// which the caller can use as a name, where user data and semantic
+// values, like an ADDR, aren't names.
 func nameFromTokLits(tokLits []tokLit) string {
 	for i := len(tokLits) - 1; i >= 0; i-- {
 		tok := tokLits[i].tok
-		if tok == token.IDENT || tok == token.STRING {
+		if tok == token.IDENT ||
+			(tok == token.STRING && tokenType(tok, tokLits[i].lit) == "STRING") {
 			return tokLits[i].lit
 		}
 	}
@@ -312,7 +607,7 @@ func cleanseName(name string) string {
 	name = strings.Trim(name, " \t\n\"")
 	if strings.IndexAny(name, "<>/ ") >= 0 ||
 		name == "true" || name == "false" ||
-		name == "ok" || name == "pid" || name == "uuid" ||
+		name == "ok" ||
 		strings.HasPrefix(name, "0x") || re_int.MatchString(name) {
 		return ""
 	}
@@ -0,0 +1,203 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// genDebugLog generates an ns_server.debug.log of n small entries,
+// where every 1000th entry is instead a config dump of about 1MB.
+func genDebugLog(n int) []byte {
+	var buf bytes.Buffer
+
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&buf, "[ns_server:debug,2016-04-14T16:10:%02d.262-07:00,"+
+			"ns_1@127.0.0.1:<0.1.0>:ns_config:init:42]", i%60)
+
+		if i%1000 != 999 {
+			fmt.Fprintf(&buf, "vbucket states: [{vbucket,%d,active},{vbucket,%d,replica}]\n", i, i+1)
+			continue
+		}
+
+		buf.WriteString("config dump:\n[")
+		for j := 0; j < 20000; j++ {
+			fmt.Fprintf(&buf, "{{node,'ns_1@127.0.0.1',memcached},[{port,%d},{dedicated_port,11209}]},\n", j)
+		}
+		buf.WriteString("{done,true}]\n")
+	}
+
+	return buf.Bytes()
+}
+
+// testFileProcessor returns a fileProcessor of a file's content, whose
+// emitted parts of the given types are written to the returned buf.
+func testFileProcessor(fname string, fmeta FileMeta, content string,
+	parts, types string) (*fileProcessor, *bytes.Buffer) {
+	run, _ := parseArgsToRun([]string{"mortimint", "-progressEvery=1000000000"})
+
+	var buf bytes.Buffer
+	run.addEmitter(parts, types, &buf)
+	run.fileProgress["d"] = map[string]int64{}
+	run.maxFNameOutLen = len("d/" + fname)
+
+	segment := &inputFile{
+		name: fname,
+		size: int64(len(content)),
+		open: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(content)), nil
+		},
+	}
+
+	return &fileProcessor{
+		run:      run,
+		dirBase:  "d",
+		fmeta:    fmeta,
+		segments: []*inputFile{segment},
+		tsInfer:  newTSInfer("", time.Date(2016, 5, 6, 6, 26, 39, 0, time.UTC), "Z"),
+		dict:     Dict{},
+		state:    map[string]string{},
+	}, &buf
+}
+
+// emittedVals returns the "[path] name = TYPE val" of each emitted
+// VALS line, without the timestamp, file and module columns.
+func emittedVals(buf *bytes.Buffer) []string {
+	var rv []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if i := strings.Index(line, " ["); i >= 0 {
+			rv = append(rv, line[i+1:])
+		}
+	}
+	return rv
+}
+
+func benchmarkProcessDebugLog(b *testing.B, maxEntryBytes, maxTokenizeBytes int) {
+	data := genDebugLog(3000)
+
+	run, _ := parseArgsToRun([]string{"mortimint", "-progressEvery=1000000000"})
+	run.MaxEntryBytes = maxEntryBytes
+	run.MaxTokenizeBytes = maxTokenizeBytes
+	run.addEmitter("FULL,VALS", "INT", ioutil.Discard)
+	run.fileProgress["b"] = map[string]int64{}
+
+	fmeta := FileMetaNS
+	fmeta.HeaderSize = 0
+
+	segment := &inputFile{
+		name: "ns_server.debug.log",
+		size: int64(len(data)),
+		open: func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		},
+	}
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		p := &fileProcessor{
+			run:      run,
+			dirBase:  "b",
+			fmeta:    fmeta,
+			segments: []*inputFile{segment},
+			tsInfer:  newTSInfer("", time.Now(), "Z"),
+			dict:     Dict{},
+			state:    map[string]string{},
+		}
+
+		err := p.process()
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkProcessDebugLog measures the throughput of a debug.log with
+// periodic giant config dumps, using the default entry limits.
+func BenchmarkProcessDebugLog(b *testing.B) {
+	benchmarkProcessDebugLog(b, 1024*1024, 256*1024)
+}
+
+// BenchmarkProcessDebugLogUnlimited is like BenchmarkProcessDebugLog,
+// but with the -maxEntryBytes and -maxTokenizeBytes limits disabled.
+func BenchmarkProcessDebugLogUnlimited(b *testing.B) {
+	benchmarkProcessDebugLog(b, 0, 0)
+}
+
+func TestIndexerBannerTS(t *testing.T) {
+	content := strings.Join([]string{"h1", "h2", "h3", "h4",
+		"2016-04-12T10:35:31.355+01:00 [Info] indexer started",
+		"==== Index Instance 12648800643524082356 ====",
+		` {"defnId":1234, "state":"INDEX_STATE_ACTIVE"}`,
+		"2016-04-12T10:35:32.355+01:00 [Info] index has 1 replicas",
+	}, "\n") + "\n"
+
+	p, buf := testFileProcessor("ns_server.indexer.log", FileMetaIndexer, content, "FULL", "")
+	if err := p.process(); err != nil {
+		t.Fatal(err)
+	}
+
+	var tss []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		tss = append(tss, strings.Fields(line)[0])
+	}
+
+	exp := []string{
+		"2016-04-12T09:35:31.355Z",
+		"2016-04-12T09:35:31.355Z", // The banner has the previous entry's time.
+		"2016-04-12T09:35:32.355Z",
+	}
+
+	if !reflect.DeepEqual(tss, exp) {
+		t.Errorf("got: %q, expected: %q", tss, exp)
+	}
+}
+
+func TestValTypeOf(t *testing.T) {
+	tests := []struct {
+		val string
+		exp string
+	}{
+		{"22", "INT"},
+		{"-5", "INT"},
+		{"0.75", "FLOAT"},
+		{"-2.5e-3", "FLOAT"},
+		{"1e6", "FLOAT"},
+		{"1.", "STRING"},
+		{".5", "STRING"},
+		{"true", "BOOL"},
+		{"false", "BOOL"},
+		{"True", "STRING"},
+		{"<ud>user::1234</ud>", "UD"},
+		{"10.0.0.2:11210", "ADDR"},
+		{"ns_1@10.0.0.2", "NODE"},
+		{"<0.123.0>", "PID"},
+		{"2016-04-14T16:02:00Z", "TS"},
+		{"user@example.com", "STRING"},
+		{"active", "STRING"},
+		{"", "STRING"},
+	}
+
+	for i, test := range tests {
+		if got := valTypeOf(test.val); got != test.exp {
+			t.Errorf("test %d, %q, got: %s, expected: %s", i, test.val, got, test.exp)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FollowPollInterval is how often a followed file is checked for
+// appended entries, rotation and truncation.
+var FollowPollInterval = 500 * time.Millisecond
+
+// FollowDictInterval is how often the dictionary is re-emitted.
+var FollowDictInterval = 10 * time.Second
+
+// ------------------------------------------------------------
+
+// processDirsFollow is like processDirs(), but never finishes, as
+// every fileProcessor keeps following its newest segment like
+// `tail -F`, and the dictionary is re-emitted periodically.
+func (run *Run) processDirsFollow() {
+	// The graph emitter is added before any follower starts, so that
+	// no tailed entries are missed.
+	if run.run["webServer"] || run.run["web"] {
+		pr, pw := io.Pipe()
+
+		run.m.Lock()
+		run.addGraphEmitter(pw)
+		run.m.Unlock()
+
+		go run.graphFollow(pr)
+	}
+
+	workCh := make(chan *fileProcessor, run.totFiles)
+
+	for _, d := range run.inputDirs {
+		err := run.processDir(d, workCh)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	close(workCh)
+
+	// Every followed file needs its own goroutine, so run.Workers
+	// isn't used here.
+	for fp := range workCh {
+		go func(fp *fileProcessor) {
+			err := fp.follow()
+			if err != nil {
+				log.Fatal(err)
+			}
+		}(fp)
+	}
+
+	for range time.Tick(FollowDictInterval) {
+		if run.EmitDict != "" {
+			run.m.Lock()
+			run.writeEmitDict()
+			run.m.Unlock()
+		}
+	}
+}
+
+// graphFollow adds the emitted VALS lines from r into the graphData
+// that's served by the web server, in batches.
+func (run *Run) graphFollow(r io.Reader) {
+	// The emitters write to r while holding run.m, so the batch has
+	// its own lock.
+	var m sync.Mutex
+	var batch *GraphData
+
+	go func() {
+		for range time.Tick(FollowPollInterval) {
+			m.Lock()
+			b := batch
+			batch = nil
+			m.Unlock()
+
+			if b != nil {
+				run.m.Lock()
+				run.graphData.Add(b)
+				run.graphData.Rev++
+				run.m.Unlock()
+			}
+		}
+	}()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, ScannerBufferCapacity)
+
+	for scanner.Scan() {
+		name, graphEntry := parseGraphLine(scanner.Text())
+		if graphEntry == nil {
+			continue
+		}
+
+		m.Lock()
+		if batch == nil {
+			batch = &GraphData{Data: map[string]GraphEntries{}}
+		}
+		batch.Data[name] = append(batch.Data[name], graphEntry)
+		m.Unlock()
+	}
+}
+
+// ------------------------------------------------------------
+
+// follow processes the older segments of a file like process(), and
+// then follows the newest segment for appended lines.  An entry is
+// only processed when it's complete, which is known when the line
+// that starts the next entry appears.
+func (p *fileProcessor) follow() error {
+	newest := p.segments[len(p.segments)-1]
+
+	for _, segment := range p.segments[0 : len(p.segments)-1] {
+		err := p.processSegment(segment)
+		if err != nil {
+			return err
+		}
+
+		p.followDone(segment, segment.size)
+	}
+
+	if newest.path == "" { // Archive members can't grow, so no following.
+		err := p.processSegment(newest)
+		if err == nil {
+			p.processEntryCurr()
+		}
+		p.followDone(newest, newest.size)
+		return err
+	}
+
+	// Live log files don't have the header that cbcollect-info adds.
+	p.fmeta.HeaderSize = 0
+
+	for {
+		err := p.followSegment(newest)
+		if err != nil {
+			return err
+		}
+
+		// The newest segment was rotated, where the next newest
+		// segment might have the same name, like ns_server's logs, or
+		// a different name, like memcached's logs.
+		newest = p.newestSegment(newest)
+	}
+}
+
+// followSegment reads a file as it grows, until the file is rotated.
+// When the file is truncated, it's read again from the start.
+func (p *fileProcessor) followSegment(segment *inputFile) error {
+	f, err := os.Open(segment.path)
+	if err != nil {
+		if os.IsNotExist(err) { // Not yet recreated after a rotation.
+			time.Sleep(FollowPollInterval)
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	p.followDone(segment, fi.Size())
+
+	var currOffset int64
+	var currLine int64
+	var partial []byte // An incomplete last line.
+
+	buf := make([]byte, 64*1024)
+
+	// readToEOF processes the complete lines that were appended.
+	readToEOF := func() error {
+		for {
+			n, err := f.Read(buf)
+			if n > 0 {
+				data := append(partial, buf[0:n]...)
+
+				for {
+					i := bytes.IndexByte(data, '\n')
+					if i < 0 {
+						break
+					}
+
+					currLine++
+
+					p.processLine(segment, string(data[0:i]), currOffset, currLine)
+
+					currOffset += int64(i + 1)
+
+					data = data[i+1:]
+				}
+
+				partial = append(partial[0:0], data...)
+			}
+
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		err = readToEOF()
+		if err != nil {
+			return err
+		}
+
+		p.followDone(segment, currOffset)
+
+		time.Sleep(FollowPollInterval)
+
+		curr, err := os.Stat(segment.path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		if err != nil || !os.SameFile(fi, curr) ||
+			p.newestSegment(segment).path != segment.path {
+			// Rotated, so drain what was appended before the rotation.
+			err = readToEOF()
+			if err != nil {
+				return err
+			}
+
+			p.followDone(segment, currOffset)
+
+			return nil
+		}
+
+		if curr.Size() < currOffset+int64(len(partial)) { // Truncated.
+			// The current entry ended with the truncation, so the
+			// rewritten file's lines aren't appended to it.
+			p.processEntryCurr()
+
+			p.entryLines = p.entryLines[0:0]
+			p.entryBytes = 0
+
+			_, err = f.Seek(0, 0)
+			if err != nil {
+				return err
+			}
+
+			currOffset, currLine, partial = 0, 0, partial[0:0]
+		}
+	}
+}
+
+// followDone publishes the progress of a followed segment, including
+// merging the dictionary entries seen so far into the run's dict.
+func (p *fileProcessor) followDone(segment *inputFile, offset int64) {
+	p.run.m.Lock()
+
+	if p.run.fileSizes[p.dirBase] == nil {
+		p.run.fileSizes[p.dirBase] = map[string]int64{}
+	}
+	if p.run.fileSizes[p.dirBase][segment.name] < offset {
+		p.run.fileSizes[p.dirBase][segment.name] = offset
+	}
+	p.run.fileProgress[p.dirBase][segment.name] = offset
+
+	p.dict.AddTo(p.run.dict)
+
+	p.run.m.Unlock()
+
+	p.dict = Dict{}
+}
+
+// newestSegment returns the newest rotated file in the directory of a
+// followed segment, which might be the segment itself.  The segment is
+// returned if the directory can't be read.
+func (p *fileProcessor) newestSegment(segment *inputFile) *inputFile {
+	dir := filepath.Dir(segment.path)
+
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return segment
+	}
+
+	fname, _ := rotatedName(segment.name)
+
+	var rv *inputFile
+	var rvOrder int64
+
+	for _, fileInfo := range fileInfos {
+		name, order := rotatedName(fileInfo.Name())
+		if name == fname && (rv == nil || order > rvOrder) {
+			rv, rvOrder = fileInfoToInputFile(dir, fileInfo), order
+		}
+	}
+
+	if rv == nil {
+		return segment
+	}
+
+	return rv
+}
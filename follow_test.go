@@ -0,0 +1,143 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewestSegment(t *testing.T) {
+	tests := []struct {
+		files   []string
+		segment string
+		exp     string
+	}{
+		{[]string{"ns_server.debug.log", "ns_server.debug.log.1", "ns_server.debug.log.2"},
+			"ns_server.debug.log.1", "ns_server.debug.log"},
+		{[]string{"memcached.log.000001.txt", "memcached.log.000002.txt", "other.log"},
+			"memcached.log.000001.txt", "memcached.log.000002.txt"},
+		{[]string{"messages-20160403", "messages"},
+			"messages-20160403", "messages"},
+		{[]string{"other.log"},
+			"info.log", "info.log"}, // Not yet recreated after a rotation.
+	}
+
+	for i, test := range tests {
+		tmp, err := ioutil.TempDir("", "mortimint_test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, fname := range test.files {
+			ioutil.WriteFile(filepath.Join(tmp, fname), []byte("x\n"), 0600)
+		}
+
+		segment := &inputFile{name: test.segment, path: filepath.Join(tmp, test.segment)}
+
+		p := &fileProcessor{}
+		if got := p.newestSegment(segment); got.name != test.exp ||
+			got.path != filepath.Join(tmp, test.exp) {
+			t.Errorf("test %d, got: %s, expected: %s", i, got.path, test.exp)
+		}
+
+		os.RemoveAll(tmp)
+	}
+}
+
+func TestFollowSegmentTruncateAndRotate(t *testing.T) {
+	defer func(d time.Duration) { FollowPollInterval = d }(FollowPollInterval)
+	FollowPollInterval = 5 * time.Millisecond
+
+	tmp, err := ioutil.TempDir("", "mortimint_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	fpath := filepath.Join(tmp, "ns_server.info.log")
+
+	write := func(lines ...string) {
+		err := ioutil.WriteFile(fpath, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("[ns_server:info,2016-04-14T16:10:09.262-07:00,ns_1@127.0.0.1:<0.1.0>:ns_log:init:42]entry a, before the truncation",
+		"  continued a")
+
+	fmeta := FileMetaNS
+	fmeta.HeaderSize = 0
+
+	p, buf := testFileProcessor("ns_server.info.log", fmeta, "", "FULL", "")
+
+	fi, _ := os.Stat(fpath)
+	segment := fileInfoToInputFile(tmp, fi)
+
+	doneCh := make(chan error)
+	go func() { doneCh <- p.followSegment(segment) }()
+
+	// waitFor waits until the emitted output has a string.
+	waitFor := func(s string) {
+		for start := time.Now(); time.Since(start) < 5*time.Second; {
+			p.run.m.Lock()
+			found := strings.Contains(buf.String(), s)
+			p.run.m.Unlock()
+			if found {
+				return
+			}
+			time.Sleep(FollowPollInterval)
+		}
+		t.Fatalf("timeout waiting for: %q, got: %s", s, buf.String())
+	}
+
+	// Nothing is emitted until entry a is complete.
+	time.Sleep(20 * FollowPollInterval)
+
+	write("  orphan b", "[ns_server:info,2016-04-14T16:10:10.262-07:00,ns_1@127.0.0.1:<0.1.0>:ns_log:init:42]entry c")
+
+	waitFor("entry a")
+
+	// Rotated, so entry c is drained and the followSegment finishes.
+	os.Rename(fpath, fpath+".1")
+	write("[ns_server:info,2016-04-14T16:10:11.262-07:00,ns_1@127.0.0.1:<0.1.0>:ns_log:init:42]entry d")
+
+	select {
+	case err := <-doneCh:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected followSegment to finish after the rotation")
+	}
+
+	p.processEntryCurr()
+
+	out := buf.String()
+
+	if !strings.Contains(out, "continued a") || strings.Contains(out, "orphan b") {
+		t.Errorf("expected entry a to end at the truncation, got: %s", out)
+	}
+
+	if !strings.Contains(out, "entry c") {
+		t.Errorf("expected entry c from the rewritten file, got: %s", out)
+	}
+
+	if strings.Contains(out, "entry d") {
+		t.Errorf("expected entry d to be left for the next segment, got: %s", out)
+	}
+}
@@ -0,0 +1,155 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// From a -formats file, which is keyed by file name or by wildcard
+// pattern, and which adds to or overrides the built-in FileMetas...
+//   {
+//     "myapp*.log": {
+//       "HeaderSize": 0,
+//       "EntryStart": "^\\d\\d\\d\\d-\\d\\d-\\d\\d ",
+//       "EntryRE": "^(?P<year>\\d\\d\\d\\d)-(?P<month>\\d\\d)-(?P<day>\\d\\d) (?P<HH>\\d\\d):(?P<MM>\\d\\d):(?P<SS>\\d\\d),(?P<SSSS>\\d+) (?P<level>\\w+) ",
+//       "Cleansers": [{"RE": "<\\d+\\.\\d+\\.\\d+>", "Replace": " \"$0\" "}]
+//     }
+//   }
+
+// FileMetaFormat is the declarative form of a FileMeta, where the
+// regexps use the same named groups as the built-in FileMetas, like
+// year, month, day, HH, MM, SS, SSSS, tz, module and level.
+type FileMetaFormat struct {
+	Skip       bool
+	HeaderSize int
+	EntryStart string   // Optional, matches a line that starts a log entry.
+	EntryRE    string   // Used to parse the first line of a log entry.
+	EntryREs   []string // Optional, used instead of EntryRE, in order.
+	ValsRE     string   // Optional, its named groups are emitted as VALS.
+
+	// Optional, regexp replacements applied in order before
+	// tokenizing a log entry.
+	Cleansers []FileMetaCleanser
+}
+
+// FileMetaCleanser is a regexp replacement, where the Replace can
+// refer to the RE's groups, like "$1".
+type FileMetaCleanser struct {
+	RE      string
+	Replace string
+}
+
+// loadFormats adds the FileMetas of a -formats file to FileMetas.
+func loadFormats(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var formats map[string]FileMetaFormat
+
+	err = json.NewDecoder(f).Decode(&formats)
+	if err != nil {
+		return fmt.Errorf("formats: %s, err: %v", path, err)
+	}
+
+	for k, format := range formats {
+		fmeta, err := format.fileMeta()
+		if err != nil {
+			return fmt.Errorf("formats: %s, file: %q, err: %v", path, k, err)
+		}
+
+		FileMetas[k] = fmeta
+	}
+
+	fileMetaWildcards = wildcardKeys(FileMetas)
+
+	return nil
+}
+
+// fileMeta compiles a FileMetaFormat into a FileMeta.
+func (format *FileMetaFormat) fileMeta() (FileMeta, error) {
+	fmeta := FileMeta{
+		Skip:       format.Skip,
+		HeaderSize: format.HeaderSize,
+	}
+
+	if format.Skip {
+		return fmeta, nil
+	}
+
+	if format.EntryRE == "" && len(format.EntryREs) <= 0 {
+		return fmeta, fmt.Errorf("an EntryRE or EntryREs is required")
+	}
+
+	var err error
+
+	compile := func(field, s string) *regexp.Regexp {
+		if s == "" || err != nil {
+			return nil
+		}
+
+		var re *regexp.Regexp
+
+		re, err = regexp.Compile(s)
+		if err != nil {
+			err = fmt.Errorf("%s: %v", field, err)
+		}
+
+		return re
+	}
+
+	if entryStart := compile("EntryStart", format.EntryStart); entryStart != nil {
+		fmeta.EntryStart = entryStart.MatchString
+	}
+
+	fmeta.EntryRE = compile("EntryRE", format.EntryRE)
+
+	for _, s := range format.EntryREs {
+		if s == "" {
+			return fmeta, fmt.Errorf("EntryREs: an empty regexp")
+		}
+		fmeta.EntryREs = append(fmeta.EntryREs, compile("EntryREs", s))
+	}
+
+	fmeta.ValsRE = compile("ValsRE", format.ValsRE)
+
+	var cleanserREs []*regexp.Regexp
+	var cleanserReplaces [][]byte
+
+	for _, cleanser := range format.Cleansers {
+		cleanserREs = append(cleanserREs, compile("Cleansers", cleanser.RE))
+		cleanserReplaces = append(cleanserReplaces, []byte(cleanser.Replace))
+	}
+
+	if err != nil {
+		return fmeta, err
+	}
+
+	if len(cleanserREs) > 0 {
+		fmeta.Cleanser = func(s []byte) []byte {
+			for i, re := range cleanserREs {
+				if re != nil {
+					s = re.ReplaceAll(s, cleanserReplaces[i])
+				}
+			}
+			return s
+		}
+	}
+
+	return fmeta, nil
+}
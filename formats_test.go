@@ -0,0 +1,94 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLoadFormats(t *testing.T) {
+	tests := []struct {
+		about  string
+		json   string
+		expErr string // A substring of the expected error, else "".
+	}{
+		{"valid",
+			`{"myapp*.log": {"HeaderSize": 1,
+			   "EntryStart": "^\\d\\d\\d\\d-",
+			   "EntryRE": "^(?P<year>\\d\\d\\d\\d)-(?P<month>\\d\\d)-(?P<day>\\d\\d) (?P<HH>\\d\\d):(?P<MM>\\d\\d):(?P<SS>\\d\\d),(?P<SSSS>\\d+) (?P<level>\\w+) ",
+			   "Cleansers": [{"RE": "<(\\d+)>", "Replace": "pid=$1"}]},
+			  "core.*": {"Skip": true}}`, ""},
+		{"bad json", `{"myapp.log": `, "formats:"},
+		{"no EntryRE", `{"myapp.log": {"HeaderSize": 1}}`, "an EntryRE or EntryREs is required"},
+		{"bad EntryRE", `{"myapp.log": {"EntryRE": "^(oops"}}`, "EntryRE:"},
+		{"bad EntryREs", `{"myapp.log": {"EntryREs": ["^a", "^(oops"]}}`, "EntryREs:"},
+		{"empty EntryREs", `{"myapp.log": {"EntryREs": [""]}}`, "EntryREs: an empty regexp"},
+		{"empty EntryREs element", `{"myapp.log": {"EntryREs": ["^a", ""]}}`, "EntryREs: an empty regexp"},
+		{"bad Cleanser", `{"myapp.log": {"EntryRE": "^a", "Cleansers": [{"RE": "[", "Replace": ""}]}}`,
+			"Cleansers:"},
+	}
+
+	defer func() {
+		delete(FileMetas, "myapp*.log")
+		delete(FileMetas, "core.*")
+		fileMetaWildcards = wildcardKeys(FileMetas)
+	}()
+
+	for _, test := range tests {
+		f, err := ioutil.TempFile("", "mortimint_test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(test.json)
+		f.Close()
+
+		err = loadFormats(f.Name())
+
+		os.Remove(f.Name())
+
+		if test.expErr == "" && err != nil {
+			t.Errorf("%s, got err: %v", test.about, err)
+		}
+		if test.expErr != "" && (err == nil || !strings.Contains(err.Error(), test.expErr)) {
+			t.Errorf("%s, got err: %v, expected: %q", test.about, err, test.expErr)
+		}
+	}
+
+	fmeta, exists := FileMetaFor("myapp-1.log")
+	if !exists || fmeta.HeaderSize != 1 || fmeta.EntryStart == nil || fmeta.Cleanser == nil {
+		t.Fatalf("expected the myapp*.log FileMeta, got: %v, %+v", exists, fmeta)
+	}
+
+	line := "2016-05-06 06:20:01,123 ERROR worker <12> failed"
+	if !fmeta.EntryStart(line) || fmeta.EntryStart("  at stack line") {
+		t.Errorf("expected EntryStart to match only the entry line")
+	}
+
+	if _, matchIndex := fmeta.entryMatch(line); !matchedSubexp(fmeta.EntryRE, matchIndex, "HH") {
+		t.Errorf("expected the EntryRE to match the entry line")
+	}
+
+	if got := string(fmeta.Cleanser([]byte(line))); !strings.Contains(got, "worker pid=12 failed") {
+		t.Errorf("expected the Cleanser to replace, got: %q", got)
+	}
+
+	if fmeta, exists := FileMetaFor("core.1234"); !exists || !fmeta.Skip {
+		t.Errorf("expected core.* to be skipped, got: %v, %+v", exists, fmeta)
+	}
+
+	if err := loadFormats("/no/such/formats.json"); err == nil {
+		t.Errorf("expected an err for a missing file")
+	}
+}
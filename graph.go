@@ -13,7 +13,7 @@ type GraphEntries []*GraphEntry
 
 func (a GraphEntries) Len() int           { return len(a) }
 func (a GraphEntries) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
-func (a GraphEntries) Less(i, j int) bool { return a[i].Ts < a[j].Ts }
+func (a GraphEntries) Less(i, j int) bool { return tsLess(a[i].Ts, a[j].Ts) }
 
 type GraphEntry struct {
 	Ts         string
@@ -24,6 +24,7 @@ type GraphEntry struct {
 	Module     string
 	Path       string
 	Val        string
+	Node       string `json:",omitempty"`
 }
 
 func (g *GraphData) Add(incoming *GraphData) {
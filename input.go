@@ -0,0 +1,565 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// inputDir represents a cbcollect-info directory or .zip archive,
+// which holds the log files to be processed.
+type inputDir struct {
+	dir     string // The path from the cmd-line args.
+	dirBase string // Ex: "cbcollect_info_ns_1@172.23.105.190_20160506-062639".
+	files   []*inputFile
+
+	// True for a live log directory of the follow run mode, whose
+	// ns_server log files don't have the "ns_server." prefix.
+	live bool
+
+	// The dirBase without the "~N" suffix that findInputDirs adds to
+	// a duplicate dirBase, so the collection time can still be parsed.
+	collectName string
+
+	// The FileMetas adopted by sniffFileMetas for the files that
+	// aren't in FileMetas, keyed by unrotated file name.
+	sniffed map[string]FileMeta
+}
+
+// inputFile represents a log file from an inputDir.
+type inputFile struct {
+	name    string // Ex: "ns_server.fts.log" or "syslog.tar.gz!/var/log/messages".
+	size    int64  // Uncompressed size in bytes.
+	modTime time.Time
+	open    func() (io.ReadCloser, error)
+
+	// The path of a regular file, which is "" for the members of an
+	// archive.  Only regular files can be followed (see follow.go).
+	path string
+}
+
+// rotatedFiles groups the files that have FileMetas by their unrotated
+// names, where the segments of each group are in chronological order,
+// like "ns_server.info.log.2", "ns_server.info.log.1", "ns_server.info.log".
+func (d *inputDir) rotatedFiles() ([]string, map[string][]*inputFile) {
+	var fnames []string
+
+	segments := map[string][]*inputFile{} // Keyed by unrotated name.
+
+	for _, file := range d.files {
+		fmeta, exists := d.fileMetaFor(file.name)
+		if !exists || fmeta.Skip {
+			continue
+		}
+
+		fname, _ := rotatedName(file.name)
+		if _, exists = FileMetas[file.name]; exists {
+			fname = file.name
+		}
+
+		if segments[fname] == nil {
+			fnames = append(fnames, fname)
+		}
+		segments[fname] = append(segments[fname], file)
+	}
+
+	for _, fname := range fnames {
+		sort.Sort(inputFilesByRotation(segments[fname]))
+	}
+
+	return fnames, segments
+}
+
+type inputFilesByRotation []*inputFile
+
+func (a inputFilesByRotation) Len() int      { return len(a) }
+func (a inputFilesByRotation) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a inputFilesByRotation) Less(i, j int) bool {
+	_, iOrder := rotatedName(a[i].name)
+	_, jOrder := rotatedName(a[j].name)
+	return iOrder < jOrder
+}
+
+// ------------------------------------------------------------
+
+// findInputDirs returns the inputDirs for the cmd-line args, which may
+// be cbcollect-info directories, .zip archives, individual log files,
+// shell-style globs of those, or folders (like a case folder) that are
+// walked recursively to find cbcollect-info directories.  The dirBase
+// of each returned inputDir is unique.  When live, the directories
+// are live log directories, like when following.
+func findInputDirs(args []string, live bool) ([]*inputDir, error) {
+	var rv []*inputDir
+
+	seen := map[string]bool{} // Keyed by cleaned path.
+
+	fileDirs := map[string]*inputDir{} // For individual files, keyed by parent dir.
+
+	var visit func(p string, walked bool) error
+
+	visit = func(p string, walked bool) error {
+		p = filepath.Clean(p)
+		if seen[p] {
+			return nil
+		}
+		seen[p] = true
+
+		fi, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(p, ".zip") && !fi.IsDir() {
+			d, err := readInputZip(p)
+			if err != nil {
+				return err
+			}
+			rv = append(rv, d)
+			return nil
+		}
+
+		if !fi.IsDir() {
+			if walked {
+				return nil // Ignore loose files when walking a folder.
+			}
+
+			parent := filepath.Dir(p)
+
+			d := fileDirs[parent]
+			if d == nil {
+				d = &inputDir{dir: parent, dirBase: path.Base(filepath.ToSlash(parent)), live: live}
+				fileDirs[parent] = d
+				rv = append(rv, d)
+			}
+			d.files = append(d.files, fileInfoToInputFile(parent, fi))
+			return nil
+		}
+
+		fileInfos, err := ioutil.ReadDir(p)
+		if err != nil {
+			return err
+		}
+
+		if hasKnownFiles(fileInfos, live) {
+			d, err := readInputDir(p)
+			if err != nil {
+				return err
+			}
+			d.live = live
+			rv = append(rv, d)
+			return nil
+		}
+
+		for _, fileInfo := range fileInfos {
+			err = visit(p+string(os.PathSeparator)+fileInfo.Name(), true)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, arg := range args {
+		paths := []string{arg}
+
+		if strings.ContainsAny(arg, "*?[") {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) <= 0 {
+				return nil, fmt.Errorf("error: no matches for %s", arg)
+			}
+			paths = matches
+		}
+
+		for _, p := range paths {
+			err := visit(p, false)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, d := range fileDirs {
+		var err error
+
+		d.files, err = expandCompressed(d.files)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Inputs from different places might have the same dirBase, like
+	// the same cbcollect-info from two case folders, so disambiguate.
+	dirBases := map[string]int{}
+	for _, d := range rv {
+		d.collectName = d.dirBase
+
+		dirBases[d.dirBase]++
+		if dirBases[d.dirBase] > 1 {
+			d.dirBase = d.dirBase + "~" + strconv.Itoa(dirBases[d.dirBase])
+		}
+	}
+
+	return rv, nil
+}
+
+// hasKnownFiles returns true when there are log files that have
+// FileMetas, like the ns_server.*.log files of a cbcollect-info, or
+// like the info.log of a live log directory.
+func hasKnownFiles(fileInfos []os.FileInfo, live bool) bool {
+	fileMetaFor := FileMetaFor
+	if live {
+		fileMetaFor = LiveFileMetaFor
+	}
+
+	for _, fileInfo := range fileInfos {
+		if fileInfo.IsDir() {
+			continue
+		}
+
+		fmeta, exists := fileMetaFor(fileInfo.Name())
+		if (exists && !fmeta.Skip) || FileMetasHasArchive(fileInfo.Name()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// readInputDir lists the files of a cbcollect-info directory.
+func readInputDir(dir string) (*inputDir, error) {
+	fileInfos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &inputDir{dir: dir, dirBase: path.Base(dir)}
+
+	for _, fileInfo := range fileInfos {
+		d.files = append(d.files, fileInfoToInputFile(dir, fileInfo))
+	}
+
+	d.files, err = expandCompressed(d.files)
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func fileInfoToInputFile(dir string, fileInfo os.FileInfo) *inputFile {
+	fpath := dir + string(os.PathSeparator) + fileInfo.Name()
+
+	return &inputFile{
+		name:    fileInfo.Name(),
+		size:    fileInfo.Size(),
+		modTime: fileInfo.ModTime(),
+		open:    func() (io.ReadCloser, error) { return os.Open(fpath) },
+		path:    fpath,
+	}
+}
+
+// readInputZip lists the members of a cbcollect-info .zip archive,
+// which usually has all its log files under a single top-level
+// folder that's used as the dirBase.
+func readInputZip(zipPath string) (*inputDir, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	d := &inputDir{
+		dir:     zipPath,
+		dirBase: strings.TrimSuffix(path.Base(zipPath), ".zip"),
+	}
+
+	for _, zf := range zr.File {
+		nameParts := strings.Split(zf.Name, "/")
+		if len(nameParts) > 2 || zf.FileInfo().IsDir() {
+			continue
+		}
+
+		if len(nameParts) == 2 {
+			d.dirBase = nameParts[0]
+		}
+
+		zname := zf.Name
+
+		d.files = append(d.files, &inputFile{
+			name:    nameParts[len(nameParts)-1],
+			size:    int64(zf.UncompressedSize64),
+			modTime: zf.ModTime(),
+			open:    func() (io.ReadCloser, error) { return openZipMember(zipPath, zname) },
+		})
+	}
+
+	d.files, err = expandCompressed(d.files)
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// openZipMember reopens the archive on every call so that concurrent
+// workers (and the web server) can each stream their own member.
+func openZipMember(zipPath, zname string) (io.ReadCloser, error) {
+	zr, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, zf := range zr.File {
+		if zf.Name == zname {
+			rc, err := zf.Open()
+			if err != nil {
+				zr.Close()
+				return nil, err
+			}
+
+			return &stackedReader{rc, []io.Closer{rc, zr}}, nil
+		}
+	}
+
+	zr.Close()
+
+	return nil, fmt.Errorf("error: no member %s in %s", zname, zipPath)
+}
+
+// ------------------------------------------------------------
+
+// expandCompressed replaces the .gz and .tar.gz files that have
+// FileMetas with their decompressed contents, where each member of a
+// .tar.gz becomes its own inputFile, like
+// "syslog.tar.gz!/var/log/messages".
+func expandCompressed(files []*inputFile) ([]*inputFile, error) {
+	var rv []*inputFile
+
+	for _, file := range files {
+		if !FileMetasHasArchive(file.name) {
+			rv = append(rv, file)
+			continue
+		}
+
+		var expanded []*inputFile
+		var err error
+
+		if strings.HasSuffix(file.name, ".tar.gz") {
+			expanded, err = readTarGz(file)
+		} else {
+			expanded, err = readGz(file)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rv = append(rv, expanded...)
+	}
+
+	return rv, nil
+}
+
+// readGz returns the decompressed form of a .gz file, whose
+// uncompressed size is from the gzip trailer (see gzSize), so that
+// it isn't decompressed until it's processed.
+func readGz(file *inputFile) ([]*inputFile, error) {
+	size, err := gzSize(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*inputFile{&inputFile{
+		name:    file.name,
+		size:    size,
+		modTime: file.modTime,
+		open:    func() (io.ReadCloser, error) { return openGz(file) },
+	}}, nil
+}
+
+// gzSize returns the uncompressed size of a .gz file from its gzip
+// trailer's ISIZE field, which is only of the last member of a
+// multi-member .gz, and is modulo 4GB, so it's only used for progress.
+func gzSize(file *inputFile) (int64, error) {
+	rc, err := file.open()
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	if s, ok := rc.(io.Seeker); ok {
+		if _, err = s.Seek(-4, 2); err != nil {
+			return 0, err
+		}
+	}
+
+	// A reader that can't seek, like a zip member, is read through,
+	// which is still cheaper than decompressing it.
+	var trailer [4]byte
+	var n int
+
+	buf := make([]byte, 32*1024)
+	for {
+		m, err := rc.Read(buf)
+		if m >= 4 {
+			copy(trailer[:], buf[m-4:m])
+		} else if m > 0 {
+			copy(trailer[:], append(trailer[m:], buf[0:m]...))
+		}
+		n += m
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if n < 4 {
+		return 0, fmt.Errorf("error: no gzip trailer in %s", file.name)
+	}
+
+	return int64(binary.LittleEndian.Uint32(trailer[:])), nil
+}
+
+func openGz(file *inputFile) (io.ReadCloser, error) {
+	rc, err := file.open()
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+
+	return &stackedReader{gz, []io.Closer{gz, rc}}, nil
+}
+
+// readTarGz returns the regular file members of a .tar.gz file.
+func readTarGz(file *inputFile) ([]*inputFile, error) {
+	rc, err := openGz(file)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var rv []*inputFile
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		member := hdr.Name
+
+		rv = append(rv, &inputFile{
+			name:    file.name + "!/" + strings.TrimLeft(member, "/"),
+			size:    hdr.Size,
+			modTime: hdr.ModTime,
+			open:    func() (io.ReadCloser, error) { return openTarMember(file, member) },
+		})
+	}
+
+	return rv, nil
+}
+
+func openTarMember(file *inputFile, member string) (io.ReadCloser, error) {
+	rc, err := openGz(file)
+	if err != nil {
+		return nil, err
+	}
+
+	tr := tar.NewReader(rc)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			rc.Close()
+			if err == io.EOF {
+				return nil, fmt.Errorf("error: no member %s in %s", member, file.name)
+			}
+			return nil, err
+		}
+
+		if hdr.Name == member {
+			return &stackedReader{tr, []io.Closer{rc}}, nil
+		}
+	}
+}
+
+// stackedReader reads from the top of a stack of readers, such as a
+// tar reader over a gzip reader over a file, and on Close() will
+// close all the closers, from top to bottom.
+type stackedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *stackedReader) Close() error {
+	var rv error
+	for _, c := range r.closers {
+		err := c.Close()
+		if err != nil && rv == nil {
+			rv = err
+		}
+	}
+	return rv
+}
+
+// ------------------------------------------------------------
+
+// skipTo advances a reader to the given offset, seeking when the
+// reader allows it, otherwise by reading and discarding bytes, where
+// a reader that can't seek, like an archive member, can't go back.
+func skipTo(r io.Reader, curr, offset int64) error {
+	if s, ok := r.(io.Seeker); ok {
+		_, err := s.Seek(offset, 0)
+		return err
+	}
+
+	if offset < curr {
+		return fmt.Errorf("error: cannot skip back from offset %d to %d", curr, offset)
+	}
+
+	if offset > curr {
+		_, err := io.CopyN(ioutil.Discard, r, offset-curr)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// noSeekReader hides the io.Seeker of a reader, like an archive member.
+type noSeekReader struct {
+	r *strings.Reader
+}
+
+func (r *noSeekReader) Read(p []byte) (int, error) { return r.r.Read(p) }
+
+func TestSkipTo(t *testing.T) {
+	tests := []struct {
+		seekable     bool
+		curr, offset int64
+		exp          string // Expected rest, or "ERR".
+	}{
+		{true, 0, 3, "3456789"},
+		{true, 5, 2, "23456789"},
+		{false, 0, 3, "3456789"},
+		{false, 0, 0, "0123456789"},
+		{false, 5, 2, "ERR"},
+	}
+
+	for i, test := range tests {
+		sr := strings.NewReader("0123456789")
+
+		var err error
+		var rest []byte
+
+		if test.seekable {
+			sr.Seek(test.curr, 0)
+			err = skipTo(sr, test.curr, test.offset)
+			rest, _ = ioutil.ReadAll(sr)
+		} else {
+			sr.Seek(test.curr, 0)
+			r := &noSeekReader{sr}
+			err = skipTo(r, test.curr, test.offset)
+			rest, _ = ioutil.ReadAll(r)
+		}
+
+		if test.exp == "ERR" {
+			if err == nil {
+				t.Errorf("test %d, expected err", i)
+			}
+			continue
+		}
+
+		if err != nil || string(rest) != test.exp {
+			t.Errorf("test %d, got rest: %q, err: %v, expected: %q",
+				i, rest, err, test.exp)
+		}
+	}
+}
+
+func TestGzSize(t *testing.T) {
+	content := strings.Repeat("2016-04-14T16:10:09.463447-07:00 INFO hello\n", 1000)
+
+	var gzBuf bytes.Buffer
+	gz := gzip.NewWriter(&gzBuf)
+	gz.Write([]byte(content))
+	gz.Close()
+
+	f, err := ioutil.TempFile("", "mortimint_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	f.Write(gzBuf.Bytes())
+	f.Close()
+
+	opens := []func() (io.ReadCloser, error){
+		func() (io.ReadCloser, error) { return os.Open(f.Name()) },
+		func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(&noSeekReader{strings.NewReader(gzBuf.String())}), nil
+		},
+	}
+
+	for i, open := range opens {
+		size, err := gzSize(&inputFile{name: "x.gz", open: open})
+		if err != nil || size != int64(len(content)) {
+			t.Errorf("test %d, got size: %d, err: %v, expected: %d",
+				i, size, err, len(content))
+		}
+	}
+}
+
+func TestFindInputDirsDuplicateCollectName(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "mortimint_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	name := "cbcollect_info_ns_1@10.0.0.1_20160506-062639"
+
+	for _, caseDir := range []string{"case1", "case2"} {
+		dir := filepath.Join(tmp, caseDir, name)
+		os.MkdirAll(dir, 0700)
+		ioutil.WriteFile(filepath.Join(dir, "ns_server.info.log"), []byte("h1\n"), 0600)
+	}
+
+	dirs, err := findInputDirs([]string{tmp}, false)
+	if err != nil || len(dirs) != 2 {
+		t.Fatalf("expected 2 dirs, got: %d, err: %v", len(dirs), err)
+	}
+
+	if dirs[0].dirBase != name || dirs[1].dirBase != name+"~2" {
+		t.Errorf("expected unique dirBases, got: %s, %s", dirs[0].dirBase, dirs[1].dirBase)
+	}
+
+	for _, d := range dirs {
+		if d.collectName != name {
+			t.Errorf("expected collectName: %s, got: %s", name, d.collectName)
+		}
+
+		ts := newTSInfer(d.collectName, time.Time{}, "-07:00").collectTS()
+		if !strings.HasPrefix(ts, "2016-05-06T06:26:39") {
+			t.Errorf("expected the collection time, got: %s", ts)
+		}
+	}
+}
+
+func TestHasKnownFilesLive(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "mortimint_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	ioutil.WriteFile(filepath.Join(tmp, "info.log"), []byte("x\n"), 0600)
+
+	fileInfos, err := ioutil.ReadDir(tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hasKnownFiles(fileInfos, false) {
+		t.Errorf("expected a loose info.log to be unknown")
+	}
+	if !hasKnownFiles(fileInfos, true) {
+		t.Errorf("expected a live info.log to be known")
+	}
+}
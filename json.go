@@ -0,0 +1,187 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// From projector, indexer and fts logs, whose entries embed JSON...
+//   2016-04-11T20:53:31.327+01:00 [Info] memstats {"Alloc":79226592, "PauseNs":[1523,2710]}
+//   2016-05-05T22:59:03.076-07:00 [INFO] managerStats: {"TotJanitorKickErr":1,
+//     "CurFeedsByIndex":{"beer-sample_fts":1}}
+
+// processEntryJSON emits the VALS of an entry, where the spans of
+// JSON objects and arrays are decoded and emitted with their exact
+// key paths, and the text around them is tokenized.
+func (p *fileProcessor) processEntryJSON(startOffset, startLine int64,
+	ol, ts, module, level string, buf []byte) {
+	textStart := 0
+
+	for i := 0; i < len(buf); i++ {
+		if !jsonStart(buf, i) {
+			continue
+		}
+
+		// An unterminated span runs to the end of the entry, so there's
+		// no later span to find, and the rest is tokenized as text.
+		end := jsonSpanEnd(buf, i)
+		if end < 0 {
+			p.warnTruncated(startLine, "unterminated JSON")
+			break
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(buf[i:end]))
+		dec.UseNumber()
+
+		// An unparsable span is skipped, to be tokenized as text,
+		// instead of being rescanned from each of its brackets.
+		var v interface{}
+		if dec.Decode(&v) != nil {
+			p.warnTruncated(startLine, "unparsable JSON")
+			i = end - 1
+			continue
+		}
+
+		text := buf[textStart:i]
+
+		p.tokenizeText(startOffset, startLine, ol, ts, module, level, text)
+
+		// The JSON is named by the word before it, like "memstats".
+		name := ""
+		if words := strings.Fields(string(text)); len(words) > 0 {
+			name = cleanseName(strings.TrimRight(words[len(words)-1], ":="))
+		}
+
+		p.emitJSON(startOffset, startLine, ol, ts, module, level, nil, name, v)
+
+		textStart = end
+		i = end - 1
+	}
+
+	p.tokenizeText(startOffset, startLine, ol, ts, module, level, buf[textStart:])
+}
+
+// jsonStart returns true when the byte at i looks like the start of a
+// JSON object or array, like `{"` or `[{`, rather than text like
+// "[worker_fn:1]".
+func jsonStart(buf []byte, i int) bool {
+	c := buf[i]
+	if c != '{' && c != '[' {
+		return false
+	}
+
+	rest := bytes.TrimLeft(buf[i+1:], " \t\r\n")
+	if len(rest) <= 0 {
+		return false
+	}
+
+	if c == '{' {
+		return rest[0] == '"' || rest[0] == '}'
+	}
+
+	return strings.IndexByte(`{["]-0123456789`, rest[0]) >= 0 ||
+		bytes.HasPrefix(rest, []byte("true")) ||
+		bytes.HasPrefix(rest, []byte("false")) ||
+		bytes.HasPrefix(rest, []byte("null"))
+}
+
+// jsonSpanEnd returns the index after the bracket that closes the
+// JSON object or array that starts at buf[start], else -1.
+func jsonSpanEnd(buf []byte, start int) int {
+	depth := 0
+	inString := false
+
+	for i := start; i < len(buf); i++ {
+		c := buf[i]
+
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth <= 0 {
+				return i + 1
+			}
+		}
+	}
+
+	return -1
+}
+
+// emitJSON emits a decoded JSON value with a name under a path, where
+// an object's values are under the path and the name, and an array's
+// elements are named by their index.
+func (p *fileProcessor) emitJSON(startOffset, startLine int64,
+	ol, ts, module, level string, path []string, name string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		sub := pathAppend(path, name)
+
+		for _, k := range sortedKeys(v) {
+			p.emitJSON(startOffset, startLine, ol, ts, module, level,
+				sub, strings.Join(strings.Fields(k), "_"), v[k])
+		}
+
+	case []interface{}:
+		sub := pathAppend(path, name)
+
+		for i, x := range v {
+			p.emitJSON(startOffset, startLine, ol, ts, module, level,
+				sub, strconv.Itoa(i), x)
+		}
+
+	case json.Number:
+		if name != "" {
+			valType := "FLOAT"
+			if _, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+				valType = "INT"
+			}
+
+			p.emitValType(startOffset, startLine, ol, ts, module, level,
+				path, name, valType, string(v))
+		}
+
+	case bool:
+		if name != "" {
+			p.emitValType(startOffset, startLine, ol, ts, module, level,
+				path, name, "BOOL", strconv.FormatBool(v))
+		}
+
+	case string:
+		if name != "" {
+			valType := semType(v)
+			if isUD(v) {
+				valType = "UD"
+			} else if valType == "" {
+				valType = "STRING"
+			}
+
+			p.emitValType(startOffset, startLine, ol, ts, module, level,
+				path, name, valType, strconv.Quote(v))
+		}
+	}
+}
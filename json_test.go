@@ -0,0 +1,102 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONSpan(t *testing.T) {
+	tests := []struct {
+		s        string
+		start    int
+		expStart bool
+		expEnd   int
+	}{
+		{`{"a":1} x`, 0, true, 7},
+		{`{}`, 0, true, 2},
+		{`x [1,[2]] y`, 2, true, 9},
+		{`[{"a":"}]"}]`, 0, true, 12},
+		{`{"a":"\"}"}`, 0, true, 11},
+		{`[true]`, 0, true, 6},
+		{`{"a":[1,2`, 0, true, -1},
+		{`[worker_fn:1]`, 0, false, 13},
+		{`{a,b}`, 0, false, 5},
+		{`[`, 0, false, -1},
+	}
+
+	for i, test := range tests {
+		buf := []byte(test.s)
+
+		if got := jsonStart(buf, test.start); got != test.expStart {
+			t.Errorf("test %d, %q, got jsonStart: %t, expected: %t",
+				i, test.s, got, test.expStart)
+		}
+
+		if got := jsonSpanEnd(buf, test.start); got != test.expEnd {
+			t.Errorf("test %d, %q, got jsonSpanEnd: %d, expected: %d",
+				i, test.s, got, test.expEnd)
+		}
+	}
+}
+
+func TestProcessEntryJSON(t *testing.T) {
+	tests := []struct {
+		entry string
+		exp   []string
+	}{
+		{`memstats {"Alloc":79226592, "PauseNs":[1523,2710]} done 3`,
+			[]string{"[memstats] Alloc = INT 79226592",
+				"[memstats PauseNs] 0 = INT 1523",
+				"[memstats PauseNs] 1 = INT 2710",
+				"[] done = INT 3"}},
+		{`stats {"b":{"c":true, "d":"10.0.0.2:11210"}} x {"e":2.5}`,
+			[]string{"[stats b] c = BOOL true",
+				`[stats b] d = ADDR "10.0.0.2:11210"`,
+				"[x] e = FLOAT 2.5"}},
+		{`list [1, {"a":2}] ok`,
+			[]string{"[list] 0 = INT 1", "[list 1] a = INT 2"}},
+		// An unparsable span is tokenized, and later spans are decoded.
+		{`bad {"a":1,,} then {"e":2}`,
+			[]string{"[bad] a = INT 1", "[then] e = INT 2"}},
+		// An unterminated span, and the rest after it, are tokenized.
+		{`stats {"a":1, "b":{"c":true} count 3`,
+			[]string{"[stats] a = INT 1", `[stats] a = STRING "b"`,
+				`[stats "b"] c = BOOL true`, "[stats] count = INT 3"}},
+		{`[worker_fn:1] n 3`,
+			[]string{"[] worker_fn = INT 1", "[] n = INT 3"}},
+		// A truncated dump with many unterminated spans.
+		{"dump " + strings.Repeat(`[{"a":`, 20000), nil},
+	}
+
+	for i, test := range tests {
+		content := "h1\nh2\nh3\nh4\n2016-04-12T10:35:31.355+01:00 [Info] " + test.entry + "\n"
+
+		p, buf := testFileProcessor("ns_server.indexer.log", FileMetaIndexer, content, "VALS",
+			"INT,FLOAT,BOOL,STRING,UD,DURATION,BYTES,PERCENT,ADDR,NODE,UUID,PID,TS")
+		p.run.MaxTokenizeBytes = 0
+
+		if err := p.process(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := emittedVals(buf); !reflect.DeepEqual(got, test.exp) {
+			if len(test.entry) > 80 {
+				test.entry = test.entry[:80] + "..."
+			}
+			t.Errorf("test %d, entry: %q, got: %q, expected: %q",
+				i, test.entry, got, test.exp)
+		}
+	}
+}
@@ -22,8 +22,10 @@ import (
 	"path"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 var ScannerBufferCapacity = 20 * 1024 * 1024
@@ -59,7 +61,10 @@ func main() {
 		path, closer := run.addEmitterFile(run.OutDir, "full.log", "FULL", "")
 		emittedFiles[path] = closer
 
-		path, closer = run.addEmitterFile(run.OutDir, "vals.log", "VALS", "INT")
+		path, closer = run.addEmitterFile(run.OutDir, "vals.log", "VALS", graphValTypesCSV())
+		emittedFiles[path] = closer
+
+		path, closer = run.addEmitterFile(run.OutDir, "events.log", "EVENT", "")
 		emittedFiles[path] = closer
 
 		if run.EmitParts != "FULL" || run.EmitTypes != "INT" {
@@ -85,10 +90,14 @@ func main() {
 	}
 
 	if len(run.emitters) > 0 {
-		run.processDirs()
+		if run.run["follow"] {
+			go run.processDirsFollow()
+		} else {
+			run.processDirs()
+		}
 	}
 
-	if len(emittedFiles) > 0 {
+	if len(emittedFiles) > 0 && !run.run["follow"] {
 		for _, f := range emittedFiles {
 			f.Close()
 		}
@@ -118,6 +127,10 @@ func main() {
 		fmt.Fprintf(os.Stderr, "\nmortimint web (ctrl-d to exit) >> ")
 
 		ioutil.ReadAll(os.Stdin)
+	} else if run.run["follow"] && len(run.emitters) > 0 {
+		fmt.Fprintf(os.Stderr, "\nmortimint following (ctrl-c to exit)...\n")
+
+		select {}
 	}
 }
 
@@ -127,13 +140,24 @@ func main() {
 type Run struct {
 	EmitDict  string // Path to optional JSON dictionary file to output.
 	EmitOrig  string // When non-"", original log entries will be emitted to stdout.
-	EmitParts string // Comma-separated list of parts of data to emit (VALS, MIDS, ENDS).
-	EmitTypes string // Comma-separated list of value types to emit (INT, STRING).
+	EmitParts string // Comma-separated list of parts of data to emit (VALS, MIDS, ENDS, EVENT).
+	EmitTypes string // Comma-separated list of value types to emit (INT, DURATION, STRING, etc).
 
-	Dirs []string // Input directories to process.
+	Formats string // Path to optional JSON file of additional FileMetas.
+
+	MaxEntryBytes    int // Bytes of a log entry that are kept, the rest are skipped.
+	MaxTokenizeBytes int // Larger log entries are emitted as FULL only.
+
+	// Input paths to process, which can be cbcollect-info directories,
+	// .zip archives, log files, globs, or folders to search recursively.
+	Dirs []string
 
 	OutDir string // Output directory to use.
 
+	Redact bool // When true, user data tags are hashed in the output.
+
+	TSFormat string // Format of emitted timestamps (utc, local, epoch).
+
 	ProgressEvery int // When > 0 emit progress every this many entries.
 
 	Run string // Comma-separated list of the kind of run, like "stdout,web".
@@ -145,6 +169,10 @@ type Run struct {
 
 	run map[string]bool // Result of parsing the Run param.
 
+	inputDirs []*inputDir // Result of reading the Dirs param.
+
+	redactor *redactor // Non-nil when Redact is true.
+
 	totFiles       int // Total number of files to process.
 	maxFNameOutLen int
 	spaces         string // len(spaces) == maxFNameOutLen, used for padding.
@@ -163,9 +191,14 @@ type Run struct {
 	emitProgress int64                       // Total number of emitXxxx() calls.
 	fileProgress map[string]map[string]int64 // Byte offsets reached.
 
-	minTS, maxTS string
+	minTS, maxTS string // In UTC.
+
+	// The last timestamp conversion, see tsConvLocked().
+	tsLast, tsLastUTC, tsLastOut string
 
 	dict Dict
+
+	graphData GraphData
 }
 
 // ------------------------------------------------------------
@@ -176,6 +209,7 @@ func parseArgsToRun(args []string) (*Run, *flag.FlagSet) {
 		fileProcessors: map[string]map[string]*fileProcessor{},
 		fileProgress:   map[string]map[string]int64{},
 		dict:           Dict{},
+		graphData:      GraphData{Data: map[string]GraphEntries{}},
 	}
 
 	flagSet := flag.NewFlagSet(args[0], flag.ExitOnError)
@@ -191,18 +225,46 @@ func parseArgsToRun(args []string) (*Run, *flag.FlagSet) {
 			"          FULL - emit full log entry, with only light parsing;\n"+
 			"          VALS - emit name=value pairs;\n"+
 			"          MIDS - uncommon; emit strings in between the name=value pairs;\n"+
-			"          ENDS - uncommon; emit string after last name=value pair.\n"+
+			"          ENDS - uncommon; emit string after last name=value pair;\n"+
+			"          EVENT - emit cluster events, like rebalances and failovers.\n"+
 			"       ")
 	flagSet.StringVar(&run.EmitTypes, "emitTypes", "INT",
 		"optional, comma-separated list of VALS value types to emit; supported values:\n"+
-			"          INT    - emit integer name=value pairs;\n"+
-			"          STRING - emit string name=value pairs.\n"+
+			"          INT    - emit integer name=value pairs, like -5;\n"+
+			"          FLOAT  - emit floating point name=value pairs, like 0.75;\n"+
+			"          BOOL   - emit true or false name=value pairs;\n"+
+			"          DURATION - emit durations, like 1.5s, as nanoseconds;\n"+
+			"          BYTES  - emit byte sizes, like 512MB, as bytes;\n"+
+			"          PERCENT - emit percentages, like 93%, as ratios, like 0.93;\n"+
+			"          STRING - emit string name=value pairs;\n"+
+			"          UD     - emit user data name=value pairs, like <ud>user::1234</ud>;\n"+
+			"          ADDR   - emit IP addresses, like 10.0.0.2:11210;\n"+
+			"          NODE   - emit erlang node names, like ns_1@10.0.0.2;\n"+
+			"          UUID   - emit uuids, like bucket uuids;\n"+
+			"          PID    - emit erlang pids, like <0.123.0>;\n"+
+			"          TS     - emit timestamps and dates within entries.\n"+
 			"       ")
+	flagSet.StringVar(&run.Formats, "formats", "",
+		"optional, path to a JSON file that defines additional log file formats,\n"+
+			"        keyed by file name or wildcard pattern, like \"myapp*.log\".")
+	flagSet.IntVar(&run.MaxEntryBytes, "maxEntryBytes", 1024*1024,
+		"optional, when > 0, the max bytes of a log entry that are kept,\n"+
+			"        where the rest of a larger log entry is skipped, except for\n"+
+			"        files with their own entry parsers, like ddocs.log and couchbase.log.")
+	flagSet.IntVar(&run.MaxTokenizeBytes, "maxTokenizeBytes", 256*1024,
+		"optional, when > 0, log entries larger than this many bytes\n"+
+			"        are only emitted as FULL, without VALS, MIDS or ENDS.")
 	flagSet.IntVar(&run.ProgressEvery, "progressEvery", 0,
 		"optional, when > 0, emit a progress to stderr after modulo this many emits.")
+	flagSet.BoolVar(&run.Redact, "redact", false,
+		"optional, when true, the contents of user data tags, like <ud>user::1234</ud>,\n"+
+			"        are replaced with a salted hash in all output, so the output\n"+
+			"        can be shared outside of support.")
 	flagSet.StringVar(&run.Run, "run", "std",
 		"optional, comma-separated list of the kind of run; supported values:\n"+
-			"          emit      - emits full/vals.log and emit.dict to outDir;\n"+
+			"          emit      - emits full/vals/events.log and emit.dict to outDir;\n"+
+			"          follow    - keep following the input files like `tail -F`,\n"+
+			"                      emitting entries as they're appended;\n"+
 			"          std       - convenience alias for \"stdin,stdout\";\n"+
 			"          stdin     - process stdin to send to web server for graphing;\n"+
 			"          stdout    - emit processed logs to stdout;\n"+
@@ -218,50 +280,83 @@ func parseArgsToRun(args []string) (*Run, *flag.FlagSet) {
 	flagSet.StringVar(&run.WebStatic, "webStatic", "",
 		"optional, directory of static web server resources;\n"+
 			"        this is useful when debugging mortimint.")
+	flagSet.StringVar(&run.TSFormat, "tsFormat", "utc",
+		"optional, format of emitted timestamps; supported values:\n"+
+			"          utc   - ISO 8601 in UTC, like 2016-04-14T23:10:09.463Z;\n"+
+			"          local - ISO 8601 with the log file's time zone offset,\n"+
+			"                  like 2016-04-14T16:10:09.463-07:00;\n"+
+			"          epoch - milliseconds since the epoch, like 1460675409463.\n"+
+			"       ")
 	flagSet.IntVar(&run.Workers, "workers", 0,
 		"optional, number of concurrent processing workers to use.\n"+
 			"       ")
 
 	flagSet.Parse(args[1:])
 
+	if run.TSFormat != "utc" && run.TSFormat != "local" && run.TSFormat != "epoch" {
+		fmt.Fprintf(os.Stderr, "error: unknown -tsFormat: %q\n", run.TSFormat)
+		flagSet.Usage()
+		os.Exit(2)
+	}
+
 	run.Dirs = flagSet.Args()
 
-	for _, dir := range run.Dirs {
-		fileInfos, err := ioutil.ReadDir(dir)
+	if run.Redact {
+		run.redactor = newRedactor()
+	}
+
+	if run.Formats != "" {
+		err := loadFormats(run.Formats)
 		if err != nil {
 			log.Fatal(err)
 		}
+	}
+
+	run.run = csvToMap(run.Run, map[string]bool{})
+
+	inputDirs, err := findInputDirs(run.Dirs, run.run["follow"])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	run.inputDirs = inputDirs
+
+	for _, d := range run.inputDirs {
+		for _, s := range d.sniffFileMetas() {
+			fmt.Fprintf(os.Stderr, "sniffed: %s\n", s)
+		}
+	}
 
-		dirBase := path.Base(dir)
+	for _, d := range run.inputDirs {
+		fnames, segments := d.rotatedFiles()
 
-		for _, fileInfo := range fileInfos {
-			fmeta, exists := FileMetas[fileInfo.Name()]
-			if exists && !fmeta.Skip {
-				run.totFiles += 1
+		run.totFiles += len(fnames)
 
-				x := len(dirBase) + len(fileInfo.Name()) + 1
+		for _, fname := range fnames {
+			for _, file := range segments[fname] {
+				x := len(d.dirBase) + len(file.name) + 1
 				if run.maxFNameOutLen < x {
 					run.maxFNameOutLen = x
 				}
 
-				if run.fileSizes[dirBase] == nil {
-					run.fileSizes[dirBase] = map[string]int64{}
+				if run.fileSizes[d.dirBase] == nil {
+					run.fileSizes[d.dirBase] = map[string]int64{}
 				}
-				run.fileSizes[dirBase][fileInfo.Name()] = fileInfo.Size()
+				run.fileSizes[d.dirBase][file.name] = file.size
 			}
 		}
 	}
 
 	run.spaces = strings.Repeat(" ", run.maxFNameOutLen+1)
 
-	run.run = csvToMap(run.Run, map[string]bool{})
-
 	return run, flagSet
 }
 
 // ------------------------------------------------------------
 
 func (run *Run) processDirs() bool {
+	startTime := time.Now()
+
 	workCh := make(chan *fileProcessor, run.totFiles)
 	doneCh := make(chan *fileProcessor)
 
@@ -282,8 +377,8 @@ func (run *Run) processDirs() bool {
 		}()
 	}
 
-	for _, dir := range run.Dirs {
-		err := run.processDir(dir, workCh)
+	for _, d := range run.inputDirs {
+		err := run.processDir(d, workCh)
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -295,7 +390,10 @@ func (run *Run) processDirs() bool {
 		fp := <-doneCh
 		run.m.Lock()
 		fp.dict.AddTo(run.dict)
-		run.fileProgress[fp.dirBase][fp.fname] = run.fileSizes[fp.dirBase][fp.fname]
+		for _, segment := range fp.segments {
+			run.fileProgress[fp.dirBase][segment.name] =
+				run.fileSizes[fp.dirBase][segment.name]
+		}
 		run.m.Unlock()
 	}
 
@@ -308,42 +406,58 @@ func (run *Run) processDirs() bool {
 	}
 	run.m.Unlock()
 
+	var totBytes int64
+	for _, fileSizes := range run.fileSizes {
+		for _, fileSize := range fileSizes {
+			totBytes += fileSize
+		}
+	}
+
+	elapsed := time.Since(startTime)
+
+	fmt.Fprintf(os.Stderr, "processed %d bytes in %s, %.1f MB/sec\n",
+		totBytes, elapsed, float64(totBytes)/1024.0/1024.0/elapsed.Seconds())
+
 	return true
 }
 
-func (run *Run) processDir(dir string, workCh chan *fileProcessor) error {
-	fileInfos, err := ioutil.ReadDir(dir)
-	if err != nil {
-		return err
-	}
-
-	dirBase := path.Base(dir)
+func (run *Run) processDir(d *inputDir, workCh chan *fileProcessor) error {
+	dirBase := d.dirBase
 
 	run.fileProcessors[dirBase] = map[string]*fileProcessor{}
 
-	for _, fileInfo := range fileInfos {
-		fname := fileInfo.Name()
-		fnameBaseParts := strings.Split(strings.Replace(fname, ".log", "", -1), ".")
+	run.m.Lock()
+	run.fileProgress[dirBase] = map[string]int64{}
+	run.m.Unlock()
+
+	fnames, segments := d.rotatedFiles()
+
+	zone := d.sniffZone()
+
+	for _, fname := range fnames {
+		fnameBaseParts := strings.Split(strings.Replace(
+			strings.TrimSuffix(strings.TrimSuffix(path.Base(fname), ".gz"), ".json"),
+			".log", "", -1), ".")
 		fnameBase := fnameBaseParts[len(fnameBaseParts)-1]
 
-		fmeta, exists := FileMetas[fname]
-		if !exists || fmeta.Skip {
-			continue
-		}
+		fmeta, _ := d.fileMetaFor(fname)
 
-		run.m.Lock()
-		run.fileProgress[dirBase] = map[string]int64{}
-		run.m.Unlock()
+		// The last segment is the newest, so use it for inferring timestamps.
+		modTime := segments[fname][len(segments[fname])-1].modTime
+
+		tsInfer := newTSInfer(d.collectName, modTime, zone)
 
 		run.fileProcessors[dirBase][fname] = &fileProcessor{
 			run:       run,
-			dir:       dir,
+			dir:       d.dir,
 			dirBase:   dirBase,
-			fname:     fname,
 			fnameBase: fnameBase,
-			fnameOut:  (dirBase + "/" + fname + run.spaces)[0:run.maxFNameOutLen],
 			fmeta:     fmeta,
+			segments:  segments[fname],
+			tsInfer:   tsInfer,
+			collectTS: tsInfer.collectTS(),
 			dict:      Dict{},
+			state:     map[string]string{},
 		}
 
 		workCh <- run.fileProcessors[dirBase][fname]
@@ -358,20 +472,32 @@ func (run *Run) processEmitDict() {
 	if run.EmitDict != "" {
 		fmt.Fprintf(os.Stderr, "emitting JSON dictionary: %s\n", run.EmitDict)
 
-		f, err := os.OpenFile(run.EmitDict, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer f.Close()
+		run.writeEmitDict()
+	}
+}
 
-		err = json.NewEncoder(f).Encode(struct {
-			MinTS string
-			MaxTS string
-			Dict  Dict
-		}{run.minTS, run.maxTS, run.dict})
-		if err != nil {
-			log.Fatal(err)
-		}
+// writeEmitDict writes to a temp file that's then renamed, so that
+// the web server never serves a partially written dictionary.
+func (run *Run) writeEmitDict() {
+	f, err := os.OpenFile(run.EmitDict+".tmp", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0666)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = json.NewEncoder(f).Encode(struct {
+		MinTS string
+		MaxTS string
+		Dict  Dict
+	}{run.minTS, run.maxTS, run.dict})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	f.Close()
+
+	err = os.Rename(run.EmitDict+".tmp", run.EmitDict)
+	if err != nil {
+		log.Fatal(err)
 	}
 }
 
@@ -384,13 +510,15 @@ func (run *Run) emitEntryFull(ts, module, level, dirBase,
 
 	run.m.Lock()
 
+	_, tsOut := run.tsConvLocked(ts)
+
 	for _, emitter := range run.emitters {
 		if emitter.emitParts["FULL"] {
 			if linesJoined == "" {
 				linesJoined = strings.Replace(strings.Join(lines, " "), "\n", " ", -1)
 			}
 
-			emitter.emitEntryFull(ts, module, level, fnameOut, ol, linesJoined)
+			emitter.emitEntryFull(tsOut, module, level, fnameOut, ol, linesJoined)
 		}
 	}
 
@@ -399,15 +527,51 @@ func (run *Run) emitEntryFull(ts, module, level, dirBase,
 	run.m.Unlock()
 }
 
+// emitEntryEvent emits a cluster event, like a rebalance, which the
+// web server overlays on its charts.
+func (run *Run) emitEntryEvent(ts, module, level, dirBase,
+	fname, fnameBase, fnameOut, ol string,
+	startOffset, startLine int64, event string) {
+	run.m.Lock()
+
+	_, tsOut := run.tsConvLocked(ts)
+
+	for _, emitter := range run.emitters {
+		emitter.emitEntryEvent(tsOut, module, level, fnameOut, ol, event)
+	}
+
+	run.emitCommonLocked(ts, dirBase, fname, startOffset)
+
+	run.m.Unlock()
+}
+
 func (run *Run) emitEntryPart(ts, module, level, dirBase,
+	fname, fnameBase, fnameOut, ol string,
+	startOffset, startLine int64, partKind string,
+	namePath []string, name, valType, val string, valQuoted bool) {
+	run.emitEntryPartGraphed(false, ts, module, level, dirBase,
+		fname, fnameBase, fnameOut, ol, startOffset, startLine, partKind,
+		namePath, name, valType, val, valQuoted)
+}
+
+// emitEntryPartGraphed is like emitEntryPart, but when graphed is
+// true, the part was already added directly to the run's graphData,
+// so it's not also emitted to the emitters that feed the graphData.
+func (run *Run) emitEntryPartGraphed(graphed bool, ts, module, level, dirBase,
 	fname, fnameBase, fnameOut, ol string,
 	startOffset, startLine int64, partKind string,
 	namePath []string, name, valType, val string, valQuoted bool) {
 	if len(val) > 0 {
 		run.m.Lock()
 
+		_, tsOut := run.tsConvLocked(ts)
+
 		for _, emitter := range run.emitters {
-			emitter.emitEntryPart(ts, module, level,
+			if graphed && emitter.graph {
+				continue
+			}
+
+			emitter.emitEntryPart(tsOut, module, level,
 				fnameOut, ol, partKind, namePath, name, valType, val, valQuoted)
 		}
 
@@ -438,13 +602,77 @@ func (run *Run) emitCommonLocked(ts, dirBase, fname string, offsetReached int64)
 		run.emitProgressBarsLocked()
 	}
 
-	if run.minTS == "" || run.minTS > ts {
-		run.minTS = ts
+	// The min and max are in UTC, so they're comparable across inputs.
+	tsUTC, _ := run.tsConvLocked(ts)
+
+	if run.minTS == "" || run.minTS > tsUTC {
+		run.minTS = tsUTC
+	}
+
+	if run.maxTS < tsUTC {
+		run.maxTS = tsUTC
+	}
+}
+
+// tsConvLocked returns a timestamp in UTC and in the TSFormat, where
+// the last conversion is cached, as an entry's many VALS share a ts.
+func (run *Run) tsConvLocked(ts string) (string, string) {
+	if ts != run.tsLast {
+		run.tsLast = ts
+		run.tsLastUTC = tsUTC(ts)
+		run.tsLastOut = tsFormat(ts, run.TSFormat)
+	}
+
+	return run.tsLastUTC, run.tsLastOut
+}
+
+// ------------------------------------------------------------
+
+// TSLayout is the layout of entry timestamps, which keep the time
+// zone offset of their log file, like "2016-04-14T16:10:09.463-07:00",
+// or "2016-04-14T23:10:09.463Z" when in UTC.
+const TSLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// tsUTC returns a timestamp converted to UTC.
+func tsUTC(ts string) string {
+	t, err := time.Parse(TSLayout, ts)
+	if err != nil {
+		return ts
 	}
 
-	if run.maxTS < ts {
-		run.maxTS = ts
+	return t.UTC().Format(TSLayout)
+}
+
+// tsFormat returns a timestamp in a -tsFormat.
+func tsFormat(ts, format string) string {
+	switch format {
+	case "local":
+		return ts
+	case "epoch":
+		t, err := time.Parse(TSLayout, ts)
+		if err != nil {
+			return ts
+		}
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	}
+
+	return tsUTC(ts)
+}
+
+// tsLess returns true when timestamp a is before b, where emitted
+// timestamps in UTC or in epoch millis compare as strings.
+func tsLess(a, b string) bool {
+	if len(a) == len(b) && tsComparable(a) && tsComparable(b) {
+		return a < b
 	}
+
+	return tsUTC(a) < tsUTC(b)
+}
+
+// tsComparable returns true when a timestamp is in UTC or in epoch
+// millis, so that it compares as a string with others of its length.
+func tsComparable(ts string) bool {
+	return strings.HasSuffix(ts, "Z") || !strings.Contains(ts, "-")
 }
 
 func (run *Run) emitProgressBarsLocked() {
@@ -471,11 +699,14 @@ func (run *Run) emitProgressBarsLocked() {
 			fsize := fileSizes[fname]
 
 			pct := 0.0
-			if fileProgress != nil {
+			if fileProgress != nil && fsize > 0 {
 				pct = float64(fileProgress[fname]) / float64(fsize)
 			}
+			if pct > 1.0 { // Followed files can grow.
+				pct = 1.0
+			}
 
-			fnameOut := (dirBase + "/" + fname + run.spaces)[0:run.maxFNameOutLen]
+			fnameOut := run.fnameOut(dirBase, fname)
 
 			fmt.Fprintf(os.Stderr, "  %s %3d %s\n",
 				fnameOut, int(pct*100.0), bars[0:int(pct*float64(len(bars)))])
@@ -484,3 +715,8 @@ func (run *Run) emitProgressBarsLocked() {
 }
 
 var bars = "================================"
+
+// fnameOut returns the space right padded "dirBase/fname".
+func (run *Run) fnameOut(dirBase, fname string) string {
+	return (dirBase + "/" + fname + run.spaces)[0:run.maxFNameOutLen]
+}
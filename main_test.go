@@ -0,0 +1,88 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestTSFormat(t *testing.T) {
+	tests := []struct {
+		ts       string
+		expUTC   string
+		expLocal string
+		expEpoch string
+	}{
+		{"2016-04-14T16:10:09.463-07:00", "2016-04-14T23:10:09.463Z",
+			"2016-04-14T16:10:09.463-07:00", "1460675409463"},
+		{"2016-04-15T00:10:09.463+01:00", "2016-04-14T23:10:09.463Z",
+			"2016-04-15T00:10:09.463+01:00", "1460675409463"},
+		{"2016-04-14T23:10:09.463Z", "2016-04-14T23:10:09.463Z",
+			"2016-04-14T23:10:09.463Z", "1460675409463"},
+		// Crossing a day, month and year boundary.
+		{"2016-01-01T01:00:00.000+05:30", "2015-12-31T19:30:00.000Z",
+			"2016-01-01T01:00:00.000+05:30", "1451590200000"},
+		// Unparsable timestamps are left as is.
+		{"", "", "", ""},
+		{"2016-04-14 16:10:09", "2016-04-14 16:10:09",
+			"2016-04-14 16:10:09", "2016-04-14 16:10:09"},
+	}
+
+	for i, test := range tests {
+		if got := tsUTC(test.ts); got != test.expUTC {
+			t.Errorf("test %d, %q, got tsUTC: %q, expected: %q", i, test.ts, got, test.expUTC)
+		}
+
+		for _, f := range []struct{ format, exp string }{
+			{"utc", test.expUTC},
+			{"local", test.expLocal},
+			{"epoch", test.expEpoch},
+		} {
+			if got := tsFormat(test.ts, f.format); got != f.exp {
+				t.Errorf("test %d, %q, format: %q, got: %q, expected: %q",
+					i, test.ts, f.format, got, f.exp)
+			}
+		}
+	}
+}
+
+func TestTSLess(t *testing.T) {
+	tests := []struct {
+		a, b          string
+		expLess       bool
+		expComparable bool // Whether a is comparable as a string.
+	}{
+		{"2016-04-14T23:10:09.463Z", "2016-04-14T23:10:09.464Z", true, true},
+		{"2016-04-14T23:10:09.464Z", "2016-04-14T23:10:09.463Z", false, true},
+		{"2016-04-14T23:10:09.463Z", "2016-04-14T23:10:09.463Z", false, true},
+		{"1460675409463", "1460675409464", true, true},
+		// Earlier in UTC, though later as a string.
+		{"2016-04-14T16:10:09.463-07:00", "2016-04-14T23:10:09.464Z", true, false},
+		{"2016-04-15T00:10:09.465+01:00", "2016-04-14T16:10:09.464-07:00", false, false},
+		{"2016-04-15T00:10:09.463+01:00", "2016-04-14T16:10:09.464-07:00", true, false},
+		{"2016-04-14T23:10:09.464Z", "2016-04-14T16:10:09.463-07:00", false, true},
+		// A missing timestamp sorts first.
+		{"", "2016-04-14T23:10:09.463Z", true, true},
+	}
+
+	for i, test := range tests {
+		if got := tsLess(test.a, test.b); got != test.expLess {
+			t.Errorf("test %d, %q < %q, got: %t, expected: %t",
+				i, test.a, test.b, got, test.expLess)
+		}
+
+		if got := tsComparable(test.a); got != test.expComparable {
+			t.Errorf("test %d, %q, got tsComparable: %t, expected: %t",
+				i, test.a, got, test.expComparable)
+		}
+	}
+}
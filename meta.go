@@ -13,7 +13,10 @@ package main
 
 import (
 	"bytes"
+	"io"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -25,6 +28,74 @@ type FileMeta struct {
 	EntryStart func(line string) bool // Optional, returns true when line starts a new log entry.
 	EntryRE    *regexp.Regexp         // Used to parse the first line of a log entry.
 	Cleanser   func([]byte) []byte    // Optional, called before tokenizing an entry.
+
+	// Optional, an ordered list of regexps that's used instead of
+	// EntryRE for files that mix entry formats, where the first one
+	// that matches is used to parse the first line of a log entry.
+	// When EntryStart is nil, a line that matches one of them starts
+	// a new log entry.
+	EntryREs []*regexp.Regexp
+
+	// Optional, when it matches the first line of a log entry, its
+	// named groups are emitted as VALS, instead of tokenizing the entry.
+	ValsRE *regexp.Regexp
+
+	// Optional, the units of ValsRE's unit-less named groups, like
+	// "ms" for a response time, so they're emitted in base units.
+	ValsUnits map[string]string
+
+	// When true, a log entry is parsed as text around erlang terms,
+	// like ns_server's, instead of tokenizing it (see erlang.go).
+	ErlangTerms bool
+
+	// When true, a log entry without a time, like an indexer.log
+	// banner, has the time of the previous log entry instead of the
+	// collection time, so that it sorts next to its neighbors.
+	InheritTS bool
+
+	// Optional, processes a log entry instead of the usual EntryRE
+	// based processing, for files that aren't line-oriented logs.
+	ProcessEntry func(p *fileProcessor, startOffset, startLine int64, lines []string)
+
+	// Optional, processes a whole file instead of splitting it into
+	// log entries, for files like JSON documents.
+	ProcessFile func(p *fileProcessor, segment *inputFile, r io.Reader) error
+}
+
+// entryStart returns true when a line starts a new log entry.
+func (fmeta *FileMeta) entryStart(line string) bool {
+	if fmeta.EntryStart != nil {
+		return fmeta.EntryStart(line)
+	}
+
+	if len(fmeta.EntryREs) <= 0 {
+		return true
+	}
+
+	for _, entryRE := range fmeta.EntryREs {
+		if entryRE.MatchString(line) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// entryMatch returns the first of the EntryREs that matches the first
+// line of a log entry, else the EntryRE, along with its match.
+func (fmeta *FileMeta) entryMatch(line string) (*regexp.Regexp, []int) {
+	for _, entryRE := range fmeta.EntryREs {
+		matchIndex := entryRE.FindStringSubmatchIndex(line)
+		if len(matchIndex) > 0 {
+			return entryRE, matchIndex
+		}
+	}
+
+	if fmeta.EntryRE == nil {
+		return nil, nil
+	}
+
+	return fmeta.EntryRE, fmeta.EntryRE.FindStringSubmatchIndex(line)
 }
 
 // ------------------------------------------------------------
@@ -44,6 +115,9 @@ type FileMeta struct {
 // From ns_server.http_access.log...
 //   172.23.123.146 - Administrator [14/Apr/2016:16:10:19 -0700] \
 //     "GET /nodes/self HTTP/1.1" 200 1727 - Python-httplib2/$Rev: 259 $
+//   172.23.123.146 - Administrator [14/Apr/2016:16:10:19 -0700] \
+//     "GET /pools/default HTTP/1.1" 200 5213 "http://172.23.123.146:8091/ui/index.html" \
+//     "Mozilla/5.0 (Macintosh)" 12
 //
 // From query...
 //   _time=2016-04-05T13:23:05.378+01:00 _level=INFO _msg=Created New Bucket default
@@ -60,35 +134,102 @@ type FileMeta struct {
 //   2016-04-12T10:17:35.286+01:00 [Info] VBRT[<-49<-travel-sample<-127.0.0.1:8091 \
 //     #MAINT_STREAM_TOPIC_bb:44:4a:7f:f5:90:d5:91] ##3b created
 //   2016-04-05T13:22:26.133+01:00 [Info] pram[:9999] registered /adminport/vbmapRequest
+//
+// From newer memcached.log.*.txt, ns_server and eventing logs...
+//   2021-03-04T10:20:30.123456+00:00 INFO 45: Client 127.0.0.1:40000 authenticated
+//   [ns_server:info,2021-03-04T10:20:30.123Z,ns_1@cb.local:<0.123.0>:ns_log:init:42]
+//   2021-03-04T10:20:30.123+00:00 [Info] Consumer::vbsStateUpdate [worker_fn:1]
+//
+// From ns_server.analytics_*.log...
+//   2021-03-04T10:20:30.123+00:00 INFO CBAS.bootstrap.AnalyticsNCApplication [main] Starting
+//
+// From ns_server.backup_service.log...
+//   2021-03-04T10:20:30.123+00:00 INFO (Rest) Starting server on :8097
+//
+// From ns_server.prometheus.log, which is in logfmt...
+//   level=info ts=2021-03-04T10:20:30.123Z caller=main.go:388 msg="Starting Prometheus"
+//   ts=2021-03-04T10:20:30.123Z caller=head.go:659 level=info component=tsdb msg="Head GC"
+//
+// From diag.log, which has the cluster's event log between sections
+// of erlang terms, like the ns_config and per-node status...
+//   nodes_info = [{struct,[{systemStats,{struct,[{cpu_utilization_rate,2.5},
+//   logs:
+//   -------------------------------
+//   2016-05-06 06:20:01.123 menelaus_sup000 ns_1@10.0.0.1 Couchbase Server has started
+//   per_node_ns_config = [{'ns_1@10.0.0.1',[{buckets,[{configs,[{"default",
+//
+// From syslog.tar.gz and systemd_journal.gz, which have no year...
+//   Apr 14 16:10:05 node-10 kernel: Out of memory: Kill process 1234 (memcached)
+//   Apr  4 09:01:02 node-10 systemd[1]: Started Couchbase Server.
 
 var ymd = `(?P<year>\d\d\d\d)-(?P<month>\d\d)-(?P<day>\d\d)`
 var hms = `T(?P<HH>\d\d):(?P<MM>\d\d):(?P<SS>\d\d)\.(?P<SSSS>\d+)`
 
-var re_ymd_hms = regexp.MustCompile(" " + ymd + hms + " ")
+var tz = `(?P<tz>Z|[-+]\d\d:?\d\d)`
 
-var re_usual = regexp.MustCompile(`^` + ymd + hms + `-\S+\s(?P<level>\S+)\s`)
+var re_usual = regexp.MustCompile(`^` + ymd + hms + tz + `\s(?P<level>\S+)\s`)
 
-var re_usual_ex = regexp.MustCompile(`^(?P<module>\w+)\s` + ymd + hms + `-\S+\s(?P<level>\S+)\s`)
+var re_usual_ex = regexp.MustCompile(`^(?P<module>\w+)\s` + ymd + hms + tz + `\s(?P<level>\S+)\s`)
 
-var re_ns = regexp.MustCompile(`^\[(?P<module>\w+):(?P<level>\w+),` + ymd + hms + `-[^,]+,`)
+var re_query_time = regexp.MustCompile(`^_time=` + ymd + hms + tz + `\s_level=(?P<level>\S+)\s(_msg=)?`)
 
-// ------------------------------------------------------------
+var re_slash_ymd_hms = regexp.MustCompile(`^(?P<year>\d\d\d\d)/(?P<month>\d\d)/(?P<day>\d\d)\s` +
+	`(?P<HH>\d\d):(?P<MM>\d\d):(?P<SS>\d\d)(\.(?P<SSSS>\d+))?\s+`)
+
+var re_bracket_level = regexp.MustCompile(`^` + ymd + hms + tz + `\s\[(?P<level>\w+)\]\s`)
+
+var re_level_java_module = regexp.MustCompile(`^` + ymd + hms + tz +
+	`\s(?P<level>[A-Z]+)\s+(?P<module>[\w.$]+)\s(\[[^\]]*\]\s)?`)
+
+var re_level_paren_module = regexp.MustCompile(`^` + ymd + hms + tz +
+	`\s(?P<level>[A-Z]+)\s\((?P<module>\w+)\)\s`)
+
+var re_logfmt_level_ts = regexp.MustCompile(`^level=(?P<level>\w+)\s+ts=` + ymd + hms + tz +
+	`(\s+caller=(?P<module>\w+)\.go:\d+)?\s`)
+
+var re_logfmt_ts_level = regexp.MustCompile(`^ts=` + ymd + hms + tz +
+	`(\s+caller=(?P<module>\w+)\.go:\d+)?\s+level=(?P<level>\w+)\s`)
+
+var re_index_instance = regexp.MustCompile(`^====\s*Index Instance\s`)
+
+var re_ns = regexp.MustCompile(`^\[(?P<module>\w+):(?P<level>\w+),` + ymd + hms + tz + `,`)
+
+var re_diag = regexp.MustCompile(`^(?:` + ymd +
+	` (?P<HH>\d\d):(?P<MM>\d\d):(?P<SS>\d\d)\.(?P<SSSS>\d+) )?(?P<module>\w+)(?: =|:|\s)`)
+
+var re_diag_start = regexp.MustCompile(`^(\d\d\d\d-\d\d-\d\d \d\d:\d\d:\d\d\.\d+ |\w+ = |logs:$|-----)`)
+
+var re_syslog = regexp.MustCompile(`^(?P<month>[A-Z][a-z][a-z])\s+(?P<day>\d+)\s` +
+	`(?P<HH>\d\d):(?P<MM>\d\d):(?P<SS>\d\d)\s\S+\s(?P<module>[^\s:\[]+)(\[\d+\])?:\s`)
 
-var stringify_replace = []byte(` "$0" `)
+var re_http_access = regexp.MustCompile(`\[(?P<day>\d\d)/(?P<month>\w\w\w)/(?P<year>\d\d\d\d):` +
+	`(?P<HH>\d\d):(?P<MM>\d\d):(?P<SS>\d\d) (?P<tz>[-+]\d\d\d\d)\] `)
 
-var hex = "[a-f0-9]"
-var hex6 = hex + hex + hex + hex + hex + hex
+var re_http_access_vals = regexp.MustCompile(`^(?P<client>\S+) \S+ (?P<user>\S+) \[[^\]]+\] ` +
+	`"(?P<method>\S+) (?P<path>\S+)[^"]*" (?P<status>\d+) (?P<size>\S+) ` +
+	`(?:"[^"]*"|\S+) "?(?P<agent>[^"]*)"?(?: (?P<duration>\d+))?`)
 
-var re_uuid = regexp.MustCompile(" " + hex6 + "[a-f0-9-_]+ ")
+// From a cbcollect-info directory name...
+//   cbcollect_info_ns_1@172.23.105.190_20160506-062639
 
-var re_addr = regexp.MustCompile(`(ns_\d+@)?\d+\.\d+\.\d+.\d+`)
+var re_collect_ts = regexp.MustCompile(`_(\d\d\d\d)(\d\d)(\d\d)-(\d\d)(\d\d)(\d\d)$`)
+
+var re_collect_node = regexp.MustCompile(`^cbcollect_info_(.+)_\d{8}-\d{6}`)
+
+var monthNums = map[string]string{
+	"Jan": "01", "Feb": "02", "Mar": "03", "Apr": "04", "May": "05", "Jun": "06",
+	"Jul": "07", "Aug": "08", "Sep": "09", "Oct": "10", "Nov": "11", "Dec": "12",
+}
+
+// ------------------------------------------------------------
 
 var re_int = regexp.MustCompile(`\d+`)
 
-var equals_bar_re = regexp.MustCompile(`=======+([^=]+)=======+`)
-var equals_bar_replace = []byte(`"$1"`)
+var re_int_only = regexp.MustCompile(`^\d+$`)
+
+var re_signed_int_only = regexp.MustCompile(`^-?\d+$`)
 
-var ns_pid_re = regexp.MustCompile(`<\d+\.\d+\.\d+>`) // <0.0.0>
+var re_float_only = regexp.MustCompile(`^-?(\d+\.\d+|\d+(\.\d+)?[eE][-+]?\d+)$`)
 
 // ------------------------------------------------------------
 
@@ -97,6 +238,28 @@ var FileMetaUsual = FileMeta{
 	EntryRE:    re_usual,
 }
 
+// FileMetaQuery represents metadata about a query log file, which
+// mixes entry formats.
+var FileMetaQuery = FileMeta{
+	HeaderSize: 4,
+	EntryREs:   []*regexp.Regexp{re_query_time, re_slash_ymd_hms, re_bracket_level},
+}
+
+// FileMetaIndexer represents metadata about an indexer log file,
+// which has index instance banners between its usual entries.
+var FileMetaIndexer = FileMeta{
+	HeaderSize: 4,
+	EntryREs:   []*regexp.Regexp{re_bracket_level, re_index_instance},
+	InheritTS:  true,
+}
+
+// FileMetaService represents metadata about the log file of a newer
+// service, where a line in one of the usual styles starts an entry.
+var FileMetaService = FileMeta{
+	HeaderSize: 4,
+	EntryREs:   []*regexp.Regexp{re_ns, re_bracket_level, re_usual},
+}
+
 // FileMetaNS represents metadata about an ns-server log file.
 var FileMetaNS = FileMeta{
 	HeaderSize: 4,
@@ -111,69 +274,93 @@ var FileMetaNS = FileMeta{
 		}
 		return unicode.IsDigit(rune(lineParts[1][0]))
 	},
-	EntryRE: re_ns,
-	Cleanser: func(s []byte) []byte {
-		// Clear out first non-matching ']'.
-		rbrack := bytes.Index(s, []byte("]"))
-		if rbrack >= 0 {
-			lbrack := bytes.Index(s, []byte("["))
-			if lbrack < 0 || rbrack < lbrack {
-				s[rbrack] = ' '
-			}
-		}
-
-		// Convert `=============PROGRESS REPORT=============`
-		// into `"PROGRESS REPORT"`
-		s = equals_bar_re.ReplaceAll(s, equals_bar_replace)
-
-		// Convert `<0.0.0>` into `"<0.0.0>"`
-		s = ns_pid_re.ReplaceAll(s, stringify_replace)
-
-		// Convert `ns_1@172.23.105.216` into ` "ns_1@172.23.105.216" `
-		s = re_addr.ReplaceAll(s, stringify_replace)
+	EntryRE:     re_ns,
+	ErlangTerms: true,
+}
 
-		// Stringify dates.
-		s = re_ymd_hms.ReplaceAll(s, stringify_replace)
+// FileMetaDiag represents metadata about a diag.log file, where its
+// event log lines and its sections of erlang terms are entries.
+var FileMetaDiag = FileMeta{
+	HeaderSize: 4,
+	EntryStart: func(line string) bool {
+		return re_diag_start.MatchString(line)
+	},
+	EntryRE:     re_diag,
+	ErlangTerms: true,
+}
 
-		// Stringify uuids.
-		s = re_uuid.ReplaceAll(s, stringify_replace)
+// FileMetaSyslog represents metadata about a syslog or journalctl
+// file, where every line is an entry.
+var FileMetaSyslog = FileMeta{
+	EntryRE: re_syslog,
+}
 
-		return s
-	},
+// FileMetaHTTPAccess represents metadata about an ns-server http
+// access log file, which is in Apache combined log format.
+var FileMetaHTTPAccess = FileMeta{
+	HeaderSize: 4,
+	EntryRE:    re_http_access,
+	ValsRE:     re_http_access_vals,
+	ValsUnits:  map[string]string{"duration": "ms"},
 }
 
 // ------------------------------------------------------------
 
-// FileMetas is keyed by file name.
+// FileMetas is keyed by file name, where files from inside a .tar.gz
+// archive, like "syslog.tar.gz!/var/log/messages", are keyed by the
+// archive name and the member's base name, like "syslog.tar.gz!messages".
 var FileMetas = map[string]FileMeta{ // Keep alphabetical...
-	// TODO: "couchbase.log".
+	"couchbase.log": {
+		EntryStart: func(line string) bool {
+			return strings.HasPrefix(line, "=====")
+		},
+		ProcessEntry: processCouchbaseEntry,
+	},
 
-	// TODO: "ddocs.log".
+	"ddocs.log": {
+		EntryStart: func(line string) bool {
+			return strings.HasPrefix(line, "=====")
+		},
+		ProcessEntry: processDdocsEntry,
+	},
 
-	// TODO: "diag.log".
+	"diag.log": FileMetaDiag,
 
 	// SKIP: "ini.log" -- not a log file.
 
-	// TODO: "master_events.log".
+	"master_events.log": {
+		ProcessEntry: processMasterEventsEntry,
+	},
 
 	"memcached.log": {
 		HeaderSize: 4,
 		EntryRE:    re_usual,
-		Cleanser: func(s []byte) []byte {
-			s = re_addr.ReplaceAll(s, stringify_replace)
-			s = re_uuid.ReplaceAll(s, stringify_replace)
-			return s
-		},
 	},
 
+	"ns_server.analytics_*.log": { // Ex: analytics_info.log, analytics_error.log.
+		HeaderSize: 4,
+		EntryREs:   []*regexp.Regexp{re_level_java_module, re_usual},
+	},
+
+	"ns_server.analytics_access.log": FileMetaHTTPAccess,
+
 	"ns_server.babysitter.log": FileMetaNS,
 
+	"ns_server.backup_service.log": {
+		HeaderSize: 4,
+		EntryREs:   []*regexp.Regexp{re_level_paren_module, re_usual},
+	},
+
+	"ns_server.cont_*": FileMetaService,
+
 	"ns_server.couchdb.log": FileMetaNS,
 
-	// TODO: "ns_server.debug.log": FileMetaNS, -- too big for now.
+	"ns_server.debug.log": FileMetaNS, // Big, see -maxEntryBytes, -maxTokenizeBytes.
 
 	"ns_server.error.log": FileMetaNS,
 
+	"ns_server.eventing.log": FileMetaService,
+
 	"ns_server.fts.log": {
 		HeaderSize: 4,
 		EntryStart: func(line string) bool {
@@ -190,20 +377,16 @@ var FileMetas = map[string]FileMeta{ // Keep alphabetical...
 		EntryRE:    re_usual_ex,
 	},
 
-	"ns_server.http_access.log": {
-		Skip:       true,
-		HeaderSize: 4,
-	},
+	"ns_server.http_access.log": FileMetaHTTPAccess,
 
-	"ns_server.http_access_internal.log": {
-		Skip:       true,
-		HeaderSize: 4,
-	},
+	"ns_server.http_access_internal.log": FileMetaHTTPAccess,
 
-	"ns_server.indexer.log": FileMetaUsual,
+	"ns_server.indexer.log": FileMetaIndexer,
 
 	"ns_server.info.log": FileMetaNS,
 
+	"ns_server.json_rpc.log": FileMetaNS,
+
 	// TODO: "ns_server.mapreduce_errors.log".
 
 	"ns_server.metakv.log": FileMetaNS,
@@ -212,7 +395,12 @@ var FileMetas = map[string]FileMeta{ // Keep alphabetical...
 
 	"ns_server.projector.log": FileMetaUsual,
 
-	"ns_server.query.log": FileMetaUsual, // TODO: Revisit.
+	"ns_server.prometheus.log": {
+		HeaderSize: 4,
+		EntryREs:   []*regexp.Regexp{re_logfmt_level_ts, re_logfmt_ts_level},
+	},
+
+	"ns_server.query.log": FileMetaQuery,
 
 	"ns_server.reports.log": FileMetaNS,
 
@@ -220,19 +408,151 @@ var FileMetas = map[string]FileMeta{ // Keep alphabetical...
 
 	"ns_server.stats.log": FileMetaNS,
 
-	// TODO: "ns_server.views.log".
+	"ns_server.views.log": FileMetaNS,
 
 	"ns_server.xdcr.log": FileMetaNS,
 
-	// TODO: "ns_server.xdcr_errors.log".
+	"ns_server.xdcr_errors.log": FileMetaNS,
 
-	// TODO: "ns_server.xdcr_trace.log".
+	"ns_server.xdcr_trace.log": {
+		ProcessEntry: processXDCRTraceEntry,
+	},
 
-	// TODO: "stats.log".
+	"stats.log": {
+		EntryStart: func(line string) bool {
+			return strings.HasPrefix(line, "=====") || strings.HasPrefix(line, "*****")
+		},
+		ProcessEntry: processStatsEntry,
+	},
+
+	"stats_archives.json": {
+		ProcessFile: processStatsArchives,
+	},
 
-	// TODO: "stats__archives.json".
+	"syslog.tar.gz!messages": FileMetaSyslog,
 
-	// TODO: "syslog.tar.gz".
+	"syslog.tar.gz!syslog": FileMetaSyslog,
 
-	// TODO: "systemd_journal.gz".
+	"systemd_journal.gz": {
+		HeaderSize: 1, // Ex: "-- Logs begin at Thu 2016-04-14 ... --".
+		EntryRE:    re_syslog,
+	},
+}
+
+// FileMetaFor returns the FileMeta for a file name, including the
+// names of rotated files and of members from inside an archive.
+func FileMetaFor(fname string) (FileMeta, bool) {
+	fmeta, exists := fileMetaLookup(fname)
+	if !exists {
+		fname, _ = rotatedName(fname)
+		fmeta, exists = fileMetaLookup(fname)
+	}
+	if !exists {
+		bang := strings.Index(fname, "!")
+		if bang > 0 {
+			fmeta, exists = fileMetaLookup(fname[0:bang+1] + path.Base(fname[bang+1:]))
+		}
+	}
+	return fmeta, exists
+}
+
+// LiveFileMetaFor is like FileMetaFor, but also handles the live logs
+// of a running ns_server, which don't have the "ns_server." prefix
+// that cbcollect-info adds, like "info.log".  It's only for the live
+// log directories of the follow run mode, as elsewhere a loose
+// "info.log" is unlikely to be from ns_server.
+func LiveFileMetaFor(fname string) (FileMeta, bool) {
+	fmeta, exists := FileMetaFor(fname)
+	if !exists {
+		fmeta, exists = FileMetaFor("ns_server." + fname)
+	}
+	return fmeta, exists
+}
+
+// fileMetaWildcards are the sorted wildcard keys of FileMetas, like
+// "ns_server.analytics_*.log", which must be refreshed whenever
+// FileMetas changes (see loadFormats).
+var fileMetaWildcards = wildcardKeys(FileMetas)
+
+// wildcardKeys returns the sorted keys of a FileMetas that have a '*'.
+func wildcardKeys(fileMetas map[string]FileMeta) []string {
+	var rv []string
+	for _, k := range sortedKeys(fileMetas) {
+		if strings.Contains(k, "*") {
+			rv = append(rv, k)
+		}
+	}
+	return rv
+}
+
+// fileMetaLookup returns the FileMeta keyed by a file name, else the
+// first FileMeta in sorted order whose wildcard key matches the file
+// name.
+func fileMetaLookup(fname string) (FileMeta, bool) {
+	fmeta, exists := FileMetas[fname]
+	if exists {
+		return fmeta, true
+	}
+
+	for _, k := range fileMetaWildcards {
+		if matched, _ := path.Match(k, fname); matched {
+			return FileMetas[k], true
+		}
+	}
+
+	return fmeta, false
+}
+
+// ------------------------------------------------------------
+
+// From ns_server's rotated logs, where a higher number is older...
+//   ns_server.debug.log.1
+//
+// From memcached's rotated logs, where a higher number is newer...
+//   memcached.log.000042.txt
+//
+// From syslog, where a higher number is older or the suffix is a date...
+//   syslog.1
+//   messages-20160410
+
+var re_rotated_txt = regexp.MustCompile(`^(.+\.log)\.(\d+)\.txt$`)
+
+var re_rotated_num = regexp.MustCompile(`^(.+)\.(\d+)$`)
+
+var re_rotated_date = regexp.MustCompile(`^(.+)-(\d{8})$`)
+
+// rotatedName returns the unrotated name of a possibly rotated file,
+// along with an order where the older rotated files are lower.  An
+// unrotated file has an order of 0.
+func rotatedName(fname string) (string, int64) {
+	if m := re_rotated_txt.FindStringSubmatch(fname); m != nil {
+		n, _ := strconv.ParseInt(m[2], 10, 64)
+		return m[1], n
+	}
+
+	if m := re_rotated_num.FindStringSubmatch(fname); m != nil {
+		n, _ := strconv.ParseInt(m[2], 10, 64)
+		return m[1], -n
+	}
+
+	if m := re_rotated_date.FindStringSubmatch(fname); m != nil {
+		n, _ := strconv.ParseInt(m[2], 10, 64)
+		return m[1], n - 100000000
+	}
+
+	return fname, 0
+}
+
+// FileMetasHasArchive returns true when the compressed file or
+// archive has FileMetas for its decompressed contents.
+func FileMetasHasArchive(fname string) bool {
+	if !strings.HasSuffix(fname, ".gz") {
+		return false
+	}
+	for k, fmeta := range FileMetas {
+		if !fmeta.Skip && (k == fname || strings.HasPrefix(k, fname+"!")) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,280 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRotatedName(t *testing.T) {
+	tests := []struct {
+		fname    string
+		expName  string
+		expOrder int64
+	}{
+		{"ns_server.debug.log", "ns_server.debug.log", 0},
+		{"ns_server.debug.log.1", "ns_server.debug.log", -1},
+		{"ns_server.debug.log.12", "ns_server.debug.log", -12},
+		{"memcached.log.000042.txt", "memcached.log", 42},
+		{"memcached.log.0.txt", "memcached.log", 0},
+		{"syslog.1", "syslog", -1},
+		{"messages-20160410", "messages", 20160410 - 100000000},
+		{"messages-2016", "messages-2016", 0},
+		{"stats.txt", "stats.txt", 0},
+	}
+
+	for i, test := range tests {
+		name, order := rotatedName(test.fname)
+		if name != test.expName || order != test.expOrder {
+			t.Errorf("test %d, %q, got: %q %d, expected: %q %d",
+				i, test.fname, name, order, test.expName, test.expOrder)
+		}
+	}
+}
+
+func TestRotatedNameOrder(t *testing.T) {
+	// From oldest to newest, each with the unrotated file last.
+	tests := [][]string{
+		{"ns_server.debug.log.3", "ns_server.debug.log.2", "ns_server.debug.log.1",
+			"ns_server.debug.log"},
+		{"memcached.log.000009.txt", "memcached.log.000010.txt"},
+		{"messages-20160403", "messages-20160410", "messages"},
+	}
+
+	for i, test := range tests {
+		var orders []int
+		for _, fname := range test {
+			_, order := rotatedName(fname)
+			orders = append(orders, int(order))
+		}
+
+		if !sort.IntsAreSorted(orders) {
+			t.Errorf("test %d, got orders: %v, expected ascending", i, orders)
+		}
+	}
+}
+
+// emittedFull returns each emitted FULL line with its whitespace
+// columns collapsed to single spaces.
+func emittedFull(lines []string, fname string, fmeta FileMeta) ([]string, error) {
+	content := strings.Join(append([]string{"h1", "h2", "h3", "h4"}, lines...), "\n") + "\n"
+
+	p, buf := testFileProcessor(fname, fmeta, content, "FULL", "")
+	if err := p.process(); err != nil {
+		return nil, err
+	}
+
+	var rv []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		rv = append(rv, strings.Join(strings.Fields(line), " "))
+	}
+	return rv, nil
+}
+
+func TestFileMetaDiag(t *testing.T) {
+	got, err := emittedFull([]string{
+		"nodes_info = [{struct,[{systemStats,{struct,[{cpu_utilization_rate,2.5},",
+		"  {swap_total,0}]}}]}]",
+		"logs:",
+		"-------------------------------",
+		"2016-05-06 06:20:01.123 menelaus_sup000 ns_1@10.0.0.1 Couchbase Server has started",
+		"2016-05-06 06:21:02.456 ns_node_disco004 ns_1@10.0.0.1 Node joined",
+		"  on port 8091",
+		"per_node_ns_config = [{'ns_1@10.0.0.1',[{buckets,[]}]}]",
+	}, "diag.log", FileMetas["diag.log"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The sections of erlang terms have no timestamps, and the event
+	// log lines have the module, continuation lines included.
+	exp := []string{
+		"- d/diag.log 12:5 nodes_info [{struct,[{systemStats,{struct,[{cpu_utilization_rate,2.5}, {swap_total,0}]}}]}]",
+		"- d/diag.log 108:7 logs",
+		"2016-05-06T06:20:01.123Z - d/diag.log 146:9 menelaus_sup000 ns_1@10.0.0.1 Couchbase Server has started",
+		"2016-05-06T06:21:02.456Z - d/diag.log 229:10 ns_node_disco004 ns_1@10.0.0.1 Node joined on port 8091",
+		"- d/diag.log 311:12 per_node_ns_config [{'ns_1@10.0.0.1',[{buckets,[]}]}]",
+	}
+
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got: %q, expected: %q", got, exp)
+	}
+}
+
+func TestFileMetaViews(t *testing.T) {
+	got, err := emittedFull([]string{
+		"[couchdb:info,2016-05-06T06:20:01.123Z,ns_1@10.0.0.1:<0.1.0>:couch_log:info:41]" +
+			"Set view `default`, main group `_design/dev_beers`, terminating with reason: normal",
+		"[views:debug,2016-05-06T06:20:02.456+01:00,ns_1@10.0.0.1:<0.2.0>:" +
+			"capi_set_view_manager:handle_mc_couch_event:523]Got set_vbucket event for default/1.",
+		"Updated state: active (1)",
+	}, "ns_server.views.log", FileMetas["ns_server.views.log"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{
+		"2016-05-06T06:20:01.123Z INFO d/ns_server.views.log 12:5 couchdb ns_1@10.0.0.1:<0.1.0>:couch_log:info:41]" +
+			"Set view `default`, main group `_design/dev_beers`, terminating with reason: normal",
+		"2016-05-06T05:20:02.456Z DEBUG d/ns_server.views.log 175:6 views ns_1@10.0.0.1:<0.2.0>:" +
+			"capi_set_view_manager:handle_mc_couch_event:523]Got set_vbucket event for default/1. Updated state: active (1)",
+	}
+
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got: %q, expected: %q", got, exp)
+	}
+}
+
+func TestFileMetaHTTPAccess(t *testing.T) {
+	tests := []struct {
+		line  string
+		expTS string
+		exp   []string
+	}{
+		{`172.23.123.146 - Administrator [14/Apr/2016:16:10:19 -0700] ` +
+			`"GET /nodes/self HTTP/1.1" 200 1727 - Python-httplib2/$Rev: 259 $`,
+			"2016-04-14T23:10:19.000Z",
+			[]string{`[] client = ADDR "172.23.123.146"`,
+				`[] user = STRING "Administrator"`,
+				`[] method = STRING "GET"`,
+				`[] path = STRING "/nodes/self"`,
+				"[] status = INT 200",
+				"[] size = INT 1727",
+				`[] agent = STRING "Python-httplib2/$Rev: 259 $"`}},
+		{`172.23.123.146 - - [14/Apr/2016:16:10:20 +0100] ` +
+			`"POST /pools/default/buckets HTTP/1.1" 202 - "http://172.23.123.146:8091/ui/index.html" ` +
+			`"Mozilla/5.0 (Macintosh)" 12`,
+			"2016-04-14T15:10:20.000Z",
+			[]string{`[] client = ADDR "172.23.123.146"`,
+				`[] method = STRING "POST"`,
+				`[] path = STRING "/pools/default/buckets"`,
+				"[] status = INT 202",
+				`[] agent = STRING "Mozilla/5.0 (Macintosh)"`,
+				"[] duration = DURATION 12000000"}},
+	}
+
+	for i, test := range tests {
+		content := "h1\nh2\nh3\nh4\n" + test.line + "\n"
+
+		p, buf := testFileProcessor("ns_server.http_access.log", FileMetas["ns_server.http_access.log"],
+			content, "VALS", "INT,STRING,DURATION,ADDR")
+
+		if err := p.process(); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := emittedVals(buf); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("test %d, got: %q, expected: %q", i, got, test.exp)
+		}
+
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if ts := strings.Fields(line)[0]; ts != test.expTS {
+				t.Errorf("test %d, got ts: %s, expected: %s", i, ts, test.expTS)
+			}
+		}
+	}
+}
+
+func TestFileMetaFor(t *testing.T) {
+	tests := []struct {
+		fname     string
+		expExists bool
+		expRE     *regexp.Regexp // The ValsRE, EntryRE or first EntryREs.
+	}{
+		// Exact keys have precedence over wildcard keys.
+		{"ns_server.analytics_access.log", true, re_http_access_vals},
+		{"ns_server.analytics_info.log", true, re_level_java_module},
+		{"ns_server.analytics_error.log.1", true, re_level_java_module},
+		{"ns_server.analytics_.log", true, re_level_java_module},
+		{"ns_server.analytics.log", false, nil},
+		{"ns_server.cont_backup.log", true, re_ns},
+		{"ns_server.eventing.log", true, re_ns},
+		{"ns_server.backup_service.log", true, re_level_paren_module},
+		{"ns_server.json_rpc.log", true, re_ns},
+		{"ns_server.prometheus.log", true, re_logfmt_level_ts},
+		{"memcached.log.000042.txt", true, re_usual},
+		{"ns_server.debug.log.3", true, re_ns},
+		{"ns_server.unknown.log", false, nil},
+	}
+
+	for i, test := range tests {
+		fmeta, exists := FileMetaFor(test.fname)
+		if exists != test.expExists {
+			t.Errorf("test %d, %q, got exists: %t, expected: %t",
+				i, test.fname, exists, test.expExists)
+			continue
+		}
+
+		re := fmeta.ValsRE
+		if re == nil {
+			re = fmeta.EntryRE
+		}
+		if re == nil && len(fmeta.EntryREs) > 0 {
+			re = fmeta.EntryREs[0]
+		}
+
+		if re != test.expRE {
+			t.Errorf("test %d, %q, got re: %v, expected: %v", i, test.fname, re, test.expRE)
+		}
+	}
+}
+
+func TestServiceFileMetas(t *testing.T) {
+	tests := []struct {
+		fname string
+		line  string
+		exp   string // The FULL line, with its columns collapsed.
+	}{
+		{"ns_server.eventing.log",
+			"2021-03-04T10:20:30.123+01:00 [Info] Consumer::vbsStateUpdate [worker_fn:1]",
+			"2021-03-04T09:20:30.123Z INFO d/ns_server.eventing.log 12:5 Consumer::vbsStateUpdate [worker_fn:1]"},
+		{"ns_server.cont_backup.log",
+			"[ns_server:info,2021-03-04T10:20:30.123Z,ns_1@cb.local:<0.123.0>:ns_log:init:42]started",
+			"2021-03-04T10:20:30.123Z INFO d/ns_server.cont_backup.log 12:5 ns_server ns_1@cb.local:<0.123.0>:ns_log:init:42]started"},
+		{"ns_server.analytics_info.log",
+			"2021-03-04T10:20:30.123+00:00 INFO CBAS.bootstrap.AnalyticsNCApplication [main] Starting",
+			"2021-03-04T10:20:30.123Z INFO d/ns_server.analytics_info.log 12:5 CBAS.bootstrap.AnalyticsNCApplication Starting"},
+		{"ns_server.backup_service.log",
+			"2021-03-04T10:20:30.123+00:00 INFO (Rest) Starting server on :8097",
+			"2021-03-04T10:20:30.123Z INFO d/ns_server.backup_service.log 12:5 Rest Starting server on :8097"},
+		{"ns_server.prometheus.log",
+			`level=info ts=2021-03-04T10:20:30.123Z caller=main.go:388 msg="Starting Prometheus"`,
+			`2021-03-04T10:20:30.123Z INFO d/ns_server.prometheus.log 12:5 main msg="Starting Prometheus"`},
+		{"ns_server.prometheus.log",
+			`ts=2021-03-04T10:20:30.123Z caller=head.go:659 level=warn component=tsdb msg="Head GC"`,
+			`2021-03-04T10:20:30.123Z WARN d/ns_server.prometheus.log 12:5 head component=tsdb msg="Head GC"`},
+		{"memcached.log.000042.txt",
+			"2021-03-04T10:20:30.123456+00:00 INFO 45: Client 127.0.0.1:40000 authenticated",
+			"2021-03-04T10:20:30.123Z INFO d/memcached.log.000042.txt 12:5 45: Client 127.0.0.1:40000 authenticated"},
+	}
+
+	for i, test := range tests {
+		fmeta, exists := FileMetaFor(test.fname)
+		if !exists {
+			t.Errorf("test %d, %q, expected a FileMeta", i, test.fname)
+			continue
+		}
+
+		got, err := emittedFull([]string{test.line}, test.fname, fmeta)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(got) != 1 || got[0] != test.exp {
+			t.Errorf("test %d, %q, got: %q, expected: %q", i, test.fname, got, test.exp)
+		}
+	}
+}
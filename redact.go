@@ -0,0 +1,130 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+	"go/token"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// From logs that tag user data, like doc keys, which a redacted
+// cbcollect-info has replaced with a hash...
+//   Deleting doc <ud>user::1234</ud> from bucket default
+//   Deleting doc <ud>c2f0a6c35ab8d62f1cd47b5e5ff69e9a5bf8f3e1</ud> from bucket default
+
+// re_ud matches a user data tag, which might span lines, like the
+// doc body of a multi-line erlang term or JSON.
+var re_ud = regexp.MustCompile(`(?s)<ud>(.*?)</ud>`)
+
+// udStringify turns each user data tag into a single quoted string,
+// so that the tokenizer emits it as one value instead of as the "<",
+// "ud" and ">" tokens.
+func udStringify(s []byte) []byte {
+	return re_ud.ReplaceAllFunc(s, func(ud []byte) []byte {
+		return []byte(" " + strconv.Quote(string(ud)) + " ")
+	})
+}
+
+// isUD returns true when a value, which might be quoted, is a user
+// data tag, like `"<ud>user::1234</ud>"`.
+func isUD(val string) bool {
+	val = strings.Trim(val, `"`)
+	return strings.HasPrefix(val, "<ud>") && strings.HasSuffix(val, "</ud>")
+}
+
+// tokenType returns the value type of a token, where a string that's
+// a user data tag has the UD type, a string that's a semantic value
+// has its semantic type, like ADDR, and true and false are BOOL.
+func tokenType(tok token.Token, lit string) string {
+	if tok == token.STRING {
+		if isUD(lit) {
+			return "UD"
+		}
+		if valType := semType(lit); valType != "" {
+			return valType
+		}
+	}
+	if tok == token.IDENT && (lit == "true" || lit == "false") {
+		return "BOOL"
+	}
+	return tok.String()
+}
+
+// ------------------------------------------------------------
+
+// redactor replaces the contents of user data tags with a salted
+// hash, like cbcollect-info's -r option, so the output can be shared
+// outside of support, where the same user data in different log
+// entries still has the same hash within a run.
+type redactor struct {
+	salt []byte
+}
+
+func newRedactor() *redactor {
+	salt := make([]byte, 16)
+	rand.Read(salt)
+
+	return &redactor{salt: salt}
+}
+
+// redact returns s with the contents of its user data tags hashed.
+// As s might be a truncated entry or a window of a file, an
+// unterminated tag at the end of s, or the rest of a tag that was
+// cut at the start of s, is also hashed, instead of passed through.
+func (r *redactor) redact(s string) string {
+	if !strings.Contains(s, "<ud>") && !strings.Contains(s, "</ud>") {
+		return s
+	}
+
+	s = re_ud.ReplaceAllStringFunc(s, func(ud string) string {
+		return "<ud>" + r.hash(ud[len("<ud>"):len(ud)-len("</ud>")]) + "</ud>"
+	})
+
+	if i := strings.LastIndex(s, "<ud>"); i >= 0 && !strings.Contains(s[i:], "</ud>") {
+		s = s[:i] + "<ud>" + r.hash(s[i+len("<ud>"):])
+	}
+
+	if j := strings.Index(s, "</ud>"); j >= 0 {
+		if i := strings.Index(s, "<ud>"); i < 0 || j < i {
+			s = r.hash(s[:j]) + s[j:]
+		}
+	}
+
+	return s
+}
+
+// hash returns the salted hash of user data.
+func (r *redactor) hash(ud string) string {
+	h := sha1.New()
+	h.Write(r.salt)
+	h.Write([]byte(ud))
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// redactLines returns the lines of a log entry with their user data
+// tags hashed, where the lines are redacted as a whole, as a tag
+// might span lines, whose lines are then replaced by its hash.
+func (r *redactor) redactLines(lines []string) []string {
+	for _, line := range lines {
+		if strings.Contains(line, "<ud>") || strings.Contains(line, "</ud>") {
+			return strings.Split(r.redact(strings.Join(lines, "\n")), "\n")
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,78 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	r := newRedactor()
+
+	h := func(ud string) string { return r.hash(ud) }
+
+	tests := []struct {
+		s   string
+		exp string
+	}{
+		{"no user data", "no user data"},
+		{"doc <ud>user::1234</ud> deleted",
+			"doc <ud>" + h("user::1234") + "</ud> deleted"},
+		{"<ud>a</ud> and <ud>b</ud>",
+			"<ud>" + h("a") + "</ud> and <ud>" + h("b") + "</ud>"},
+		{"body <ud>{\"secret\":\n  \"x\"}</ud> done",
+			"body <ud>" + h("{\"secret\":\n  \"x\"}") + "</ud> done"},
+		// An unterminated tag, like of a truncated entry.
+		{"doc <ud>a</ud> body <ud>{\"secret\":",
+			"doc <ud>" + h("a") + "</ud> body <ud>" + h("{\"secret\":")},
+		// A tag that was cut at the start, like of a window of a file.
+		{"ret\"}</ud> done <ud>b</ud>",
+			h("ret\"}") + "</ud> done <ud>" + h("b") + "</ud>"},
+	}
+
+	for i, test := range tests {
+		if got := r.redact(test.s); got != test.exp {
+			t.Errorf("test %d, got: %q, expected: %q", i, got, test.exp)
+		}
+	}
+}
+
+func TestRedactMultiLineEntry(t *testing.T) {
+	content := strings.Join([]string{"h1", "h2", "h3", "h4",
+		"[ns_server:debug,2016-04-14T16:10:05.262-07:00,ns_1@127.0.0.1:<0.1.0>:capi:doc:42]" +
+			"doc body <ud>{\"name\":\"Alice\",",
+		"  \"ssn\":\"123-45-6789\"}</ud> saved",
+		"[ns_server:debug,2016-04-14T16:10:06.262-07:00,ns_1@127.0.0.1:<0.1.0>:capi:doc:43]" +
+			"doc <ud>bob",
+	}, "\n") + "\n"
+
+	p, buf := testFileProcessor("ns_server.debug.log", FileMetaNS, content,
+		"FULL,VALS", "STRING,UD")
+	p.run.redactor = newRedactor()
+
+	if err := p.process(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := buf.String()
+
+	for _, secret := range []string{"Alice", "123-45-6789", "bob"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("expected %q to be redacted, got: %s", secret, out)
+		}
+	}
+
+	if !strings.Contains(out, "</ud> saved") {
+		t.Errorf("expected the text after the user data, got: %s", out)
+	}
+}
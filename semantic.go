@@ -0,0 +1,122 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// From log entries that refer to nodes, processes and documents...
+//   2016-04-14T16:10:09.463447-07:00 NOTICE connected to 10.0.0.2:11210
+//   2016-04-14T16:10:09.463447-07:00 INFO node ns_1@10.0.0.2 down, pid <0.123.0>
+//   2016-04-14T16:10:09.463447-07:00 INFO bucket uuid 6f4d2c6b0a0e4bd7a8b1d1a45c2f0f3e
+//   2016-04-14T16:10:09.463447-07:00 INFO since 2016-04-14T16:02:00Z
+
+var sem_pid = `<\d+\.\d+\.\d+>`
+
+// sem_node matches the erlang node names of couchbase, like ns_1,
+// n_0 or babysitter_of_ns_1, at an IP address or a hostname, rather
+// than any name@host, which would also match email addresses.
+var sem_node = `'?\b((babysitter_of_|couchdb_)?(ns|n)_\d+|ns_couchdb|executioner)` +
+	`@(\d{1,3}(\.\d{1,3}){3}|[a-zA-Z][\w-]*(\.[\w-]+)*)\b'?`
+
+var sem_uuid = `\b([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}|[0-9a-f]{32})\b`
+
+var sem_ts = `\b\d{4}-\d\d-\d\d([T ]\d\d:\d\d:\d\d(\.\d+)?(Z|[-+]\d\d:?\d\d)?)?\b`
+
+var sem_addr = `\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`
+
+// re_sem matches a value of any of the semantic value types.
+var re_sem = regexp.MustCompile(strings.Join(
+	[]string{sem_pid, sem_node, sem_uuid, sem_ts, sem_addr}, "|"))
+
+// semTypes are the semantic value types, whose values are emitted
+// with their original text, like STRING's, but are typed so that
+// they can be accounted for and queried separately.
+var semTypes = map[string]bool{
+	"ADDR": true, "NODE": true, "UUID": true, "PID": true, "TS": true,
+}
+
+// semOnlyREs match the whole of a value of a semantic value type.
+var semOnlyREs = []struct {
+	valType string
+	re      *regexp.Regexp
+}{
+	{"PID", regexp.MustCompile(`^(` + sem_pid + `)$`)},
+	{"NODE", regexp.MustCompile(`^(` + sem_node + `)$`)},
+	{"UUID", regexp.MustCompile(`^(` + sem_uuid + `)$`)},
+	{"TS", regexp.MustCompile(`^(` + sem_ts + `)$`)},
+	{"ADDR", regexp.MustCompile(`^(` + sem_addr + `)$`)},
+}
+
+// semType returns the semantic value type of a value, which might be
+// quoted, like "ADDR" for `"10.0.0.2:11210"`, else "".
+func semType(val string) string {
+	val = strings.Trim(val, `"'`)
+
+	for _, semOnlyRE := range semOnlyREs {
+		if semOnlyRE.re.MatchString(val) {
+			return semOnlyRE.valType
+		}
+	}
+
+	return ""
+}
+
+// semStringify turns each semantic value that isn't already within a
+// quoted string into a single quoted string, so that the tokenizer
+// emits it as one value instead of as pieces, like the INT's and
+// FLOAT's of an IP address.
+func semStringify(s []byte) []byte {
+	matches := re_sem.FindAllIndex(s, -1)
+	if len(matches) <= 0 {
+		return s
+	}
+
+	var rv []byte
+
+	last, scanned := 0, 0
+	inString := false
+
+	for _, m := range matches {
+		for ; scanned < m[0]; scanned++ {
+			if inString && s[scanned] == '\\' {
+				scanned++
+			} else if s[scanned] == '"' {
+				inString = !inString
+			}
+		}
+
+		if inString || scanned > m[0] || inEmail(s, m) {
+			continue
+		}
+
+		rv = append(rv, s[last:m[0]]...)
+		rv = append(rv, ' ')
+		rv = append(rv, strconv.Quote(strings.Trim(string(s[m[0]:m[1]]), "'"))...)
+		rv = append(rv, ' ')
+
+		last, scanned = m[1], m[1]
+	}
+
+	return append(rv, s[last:]...)
+}
+
+// inEmail returns true when a match is a node name that's the tail of
+// an email address, like the "ns_1@example.com" of
+// "jo.ns_1@example.com".
+func inEmail(s []byte, m []int) bool {
+	return m[0] > 0 && strings.IndexByte(".+-%", s[m[0]-1]) >= 0 &&
+		semType(string(s[m[0]:m[1]])) == "NODE"
+}
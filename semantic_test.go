@@ -0,0 +1,79 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestSemType(t *testing.T) {
+	tests := []struct {
+		val string
+		exp string
+	}{
+		{"<0.123.0>", "PID"},
+		{"ns_1@10.0.0.2", "NODE"},
+		{"'ns_1@127.0.0.1'", "NODE"},
+		{`"n_0@127.0.0.1"`, "NODE"},
+		{"babysitter_of_ns_1@cb.local", "NODE"},
+		{"couchdb_ns_1@cb1.example.com", "NODE"},
+		{"ns_couchdb@10.0.0.2", "NODE"},
+		{"user@example.com", ""},
+		{"first.last@example.com", ""},
+		{"ns_1@", ""},
+		{"ns_1@10.0.0.2:11210", ""},
+		{"6f4d2c6b0a0e4bd7a8b1d1a45c2f0f3e", "UUID"},
+		{"6f4d2c6b-0a0e-4bd7-a8b1-d1a45c2f0f3e", "UUID"},
+		{"2016-04-14T16:02:00Z", "TS"},
+		{"2016-04-14T16:10:09.463447-07:00", "TS"},
+		{"2016-04-14", "TS"},
+		{"10.0.0.2:11210", "ADDR"},
+		{"10.0.0.2", "ADDR"},
+		{"10.0.0", ""},
+		{"22", ""},
+		{"default", ""},
+	}
+
+	for i, test := range tests {
+		if got := semType(test.val); got != test.exp {
+			t.Errorf("test %d, %q, got: %q, expected: %q", i, test.val, got, test.exp)
+		}
+	}
+}
+
+func TestSemStringify(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp string
+	}{
+		{"connected to 10.0.0.2:11210",
+			`connected to  "10.0.0.2:11210" `},
+		{"node ns_1@10.0.0.2 down, pid <0.123.0>",
+			`node  "ns_1@10.0.0.2"  down, pid  "<0.123.0>" `},
+		{"node 'ns_1@127.0.0.1' up",
+			`node  "ns_1@127.0.0.1"  up`},
+		{`already "quoted 10.0.0.2" and 10.0.0.3`,
+			`already "quoted 10.0.0.2" and  "10.0.0.3" `},
+		{`escaped "a\" 10.0.0.2" 10.0.0.3`,
+			`escaped "a\" 10.0.0.2"  "10.0.0.3" `},
+		{"mail user@example.com about it", "mail user@example.com about it"},
+		{"mail jo.ns_1@example.com or jo-n_0@example.com",
+			"mail jo.ns_1@example.com or jo-n_0@example.com"},
+		{"no values here", "no values here"},
+	}
+
+	for i, test := range tests {
+		if got := string(semStringify([]byte(test.s))); got != test.exp {
+			t.Errorf("test %d, got: %q, expected: %q", i, got, test.exp)
+		}
+	}
+}
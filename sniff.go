@@ -0,0 +1,212 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sniffLines is the number of non-blank lines of an unknown file that
+// are matched against the EntryREs of the known FileMetas.
+var sniffLines = 50
+
+// sniffMinMatches is the number of sampled lines that must start a
+// timestamped log entry for an unknown file to be adopted, unless
+// the whole file was sampled.
+var sniffMinMatches = 3
+
+// sniffStartLines is the number of non-blank lines, which allows for a
+// header, within which an unknown file must start a log entry.
+var sniffStartLines = 10
+
+// fileMetaFor returns the FileMeta of a file, which is either a known
+// FileMeta or one that was adopted by sniffFileMetas.
+func (d *inputDir) fileMetaFor(fname string) (FileMeta, bool) {
+	fileMetaFor := FileMetaFor
+	if d.live {
+		fileMetaFor = LiveFileMetaFor
+	}
+
+	fmeta, exists := fileMetaFor(fname)
+	if !exists {
+		unrotated, _ := rotatedName(fname)
+		fmeta, exists = d.sniffed[unrotated]
+	}
+	return fmeta, exists
+}
+
+// sniffFileMetas samples the first lines of the files that don't have
+// FileMetas, and adopts the FileMeta whose EntryREs clearly match the
+// most lines, where the rotated segments of a file are sniffed only
+// once.  It returns a report line for each sniffed file, like
+// "adopted myapp.log as ns_server.projector.log" or "skipped core.1234".
+func (d *inputDir) sniffFileMetas() []string {
+	var report []string
+
+	candidates := sniffCandidates()
+
+	for _, file := range d.files {
+		if _, exists := d.fileMetaFor(file.name); exists {
+			continue
+		}
+
+		unrotated, _ := rotatedName(file.name)
+
+		if d.sniffed == nil {
+			d.sniffed = map[string]FileMeta{}
+		}
+
+		k, headerSize, why := sniffFile(file, candidates)
+		if k == "" {
+			d.sniffed[unrotated] = FileMeta{Skip: true}
+
+			report = append(report,
+				fmt.Sprintf("skipped %s/%s, %s", d.dirBase, file.name, why))
+			continue
+		}
+
+		fmeta := FileMetas[k]
+		fmeta.HeaderSize = headerSize
+
+		d.sniffed[unrotated] = fmeta
+
+		report = append(report,
+			fmt.Sprintf("adopted %s/%s as %s, %s", d.dirBase, file.name, k, why))
+	}
+
+	return report
+}
+
+// sniffCandidates returns the keys of the FileMetas that parse log
+// entries with regexps, skipping those that share the regexps of an
+// earlier key in sorted order, like the many FileMetaNS files.
+func sniffCandidates() []string {
+	var rv []string
+
+	seen := map[*regexp.Regexp]bool{}
+
+	for _, k := range sortedKeys(FileMetas) {
+		fmeta := FileMetas[k]
+		if fmeta.Skip || fmeta.ProcessEntry != nil || fmeta.ProcessFile != nil {
+			continue
+		}
+
+		entryREs := fmeta.EntryREs
+		if len(entryREs) <= 0 {
+			entryREs = []*regexp.Regexp{fmeta.EntryRE}
+		}
+
+		if entryREs[0] == nil || seen[entryREs[0]] {
+			continue
+		}
+		seen[entryREs[0]] = true
+
+		rv = append(rv, k)
+	}
+
+	return rv
+}
+
+// sniffFile returns the key of the candidate FileMeta that clearly
+// matches the first lines of a file, along with the number of header
+// lines before the first log entry, or "" with the reason why none
+// did.  A line only counts as a match when the regexp matched a
+// timestamp, so that a loose regexp, like diag.log's, whose timestamp
+// is optional, doesn't adopt arbitrary text.
+func sniffFile(file *inputFile, candidates []string) (string, int, string) {
+	f, err := file.open()
+	if err != nil {
+		return "", 0, fmt.Sprintf("err: %v", err)
+	}
+	defer f.Close()
+
+	var lines []string
+	var lineNums []int
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, ScannerBufferCapacity)
+
+	more := false
+
+	for lineNum := 0; scanner.Scan(); lineNum++ {
+		line := scanner.Text()
+		if strings.IndexByte(line, 0) >= 0 {
+			return "", 0, "binary"
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if len(lines) >= sniffLines {
+			more = true
+			break
+		}
+		lines = append(lines, line)
+		lineNums = append(lineNums, lineNum)
+	}
+
+	if len(lines) <= 0 {
+		return "", 0, "empty"
+	}
+
+	minMatches := sniffMinMatches
+	if !more { // A short file might only have a few, long entries.
+		minMatches = 1
+	}
+
+	var best string
+	var bestFirst, bestMatches int
+
+	for _, k := range candidates {
+		fmeta := FileMetas[k]
+
+		first := -1
+		matches := 0
+
+		for i, line := range lines {
+			entryRE, matchIndex := fmeta.entryMatch(line)
+			if len(matchIndex) > 0 && matchedSubexp(entryRE, matchIndex, "HH") {
+				if first < 0 {
+					first = i
+				}
+				matches++
+			}
+		}
+
+		// Continuation lines, like stack traces, are allowed, but the
+		// file must look like a log file from its start.
+		if first >= 0 && first < sniffStartLines &&
+			matches >= minMatches && matches > bestMatches {
+			best, bestFirst, bestMatches = k, first, matches
+		}
+	}
+
+	if best == "" {
+		return "", 0, "no matching format"
+	}
+
+	return best, lineNums[bestFirst],
+		fmt.Sprintf("%d of %d lines matched", bestMatches, len(lines))
+}
+
+// matchedSubexp returns true when a regexp's named group took part in
+// a match, given the match's submatch indexes.
+func matchedSubexp(re *regexp.Regexp, matchIndex []int, name string) bool {
+	for i, subexpName := range re.SubexpNames() {
+		if subexpName == name {
+			return 2*i < len(matchIndex) && matchIndex[2*i] >= 0
+		}
+	}
+	return false
+}
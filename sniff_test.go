@@ -0,0 +1,73 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestSniffFile(t *testing.T) {
+	memcachedLines := strings.Repeat(
+		"2016-04-14T16:10:09.463447-07:00 NOTICE connected to 10.0.0.2:11210\n", 60)
+
+	tests := []struct {
+		about         string
+		content       string
+		expK          string
+		expHeaderSize int
+		expWhy        string
+	}{
+		{"memcached style",
+			memcachedLines, "memcached.log", 0, "50 of 50 lines matched"},
+		{"memcached style after a header and blank lines",
+			"my app v1.0\n\nstarted\n" + memcachedLines, "memcached.log", 3, "48 of 50 lines matched"},
+		{"a short file of one entry",
+			"2016-04-14T16:10:09.463447-07:00 NOTICE hello\n  continued\n",
+			"memcached.log", 0, "1 of 2 lines matched"},
+		{"too few entries",
+			"2016-04-14T16:10:09.463447-07:00 NOTICE hello\n" + strings.Repeat("text\n", 60),
+			"", 0, "no matching format"},
+		{"entries after a long header",
+			strings.Repeat("header\n", 10) + memcachedLines, "", 0, "no matching format"},
+		{"text",
+			strings.Repeat("just some text\n", 60), "", 0, "no matching format"},
+		{"empty",
+			"\n\n", "", 0, "empty"},
+		{"binary",
+			"ELF\x00\x01\x02\n", "", 0, "binary"},
+	}
+
+	candidates := sniffCandidates()
+
+	for _, test := range tests {
+		content := test.content
+
+		file := &inputFile{
+			name: "myapp.log",
+			size: int64(len(content)),
+			open: func() (io.ReadCloser, error) {
+				return ioutil.NopCloser(strings.NewReader(content)), nil
+			},
+		}
+
+		k, headerSize, why := sniffFile(file, candidates)
+		if (k == "") != (test.expK == "") ||
+			(k != "" && FileMetas[k].EntryRE != FileMetas[test.expK].EntryRE) ||
+			headerSize != test.expHeaderSize || why != test.expWhy {
+			t.Errorf("%s, got: %q %d %q, expected: %q %d %q", test.about,
+				k, headerSize, why, test.expK, test.expHeaderSize, test.expWhy)
+		}
+	}
+}
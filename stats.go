@@ -0,0 +1,240 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// From stats.log, which has cbstats dumps per bucket, where each
+// kind of dump has a section header, and each bucket has a "*****"
+// line followed by the bucket name...
+//   ==============================================================================
+//   memcached stats all
+//   ['cbstats', '-a', '127.0.0.1:11210', 'all', '-b', '_admin', '-p', '****']
+//   ==============================================================================
+//   ******************************************************************************
+//   default
+//
+//    curr_items:                                            7303
+//    mem_used:                                              27438120
+//    time:                                                  1462541199
+//
+// Checkpoint and similar dumps have names like "vb_0:num_items".
+
+var re_stat = regexp.MustCompile(`^\s*(\S+):\s+(\S.*)$`)
+
+// processStatsEntry processes a stats.log entry, which is either a
+// section header or a bucket's stats.  Each stat is emitted as VALS
+// with a path of the bucket name and section, like "[default all]",
+// and with a timestamp from the bucket's "time" stat.
+func processStatsEntry(p *fileProcessor, startOffset, startLine int64, lines []string) {
+	if strings.HasPrefix(lines[0], "=====") {
+		if len(lines) >= 2 && strings.TrimSpace(lines[1]) != "" {
+			section := strings.Fields(lines[1]) // Ex: "memcached stats all".
+			p.state["section"] = section[len(section)-1]
+		}
+		return
+	}
+
+	if len(lines) < 2 {
+		return
+	}
+
+	bucket := strings.TrimSpace(lines[1])
+
+	var names, vals []string
+
+	for _, line := range lines[2:] {
+		m := re_stat.FindStringSubmatch(line)
+		if m != nil {
+			names = append(names, m[1])
+			vals = append(vals, strings.TrimSpace(m[2]))
+
+			if m[1] == "time" {
+				secs, err := strconv.ParseInt(m[2], 10, 64)
+				if err == nil {
+					p.state["time:"+bucket] = time.Unix(secs, 0).UTC().Format(TSLayout)
+				}
+			}
+		}
+	}
+
+	ts := p.state["time:"+bucket]
+	if ts == "" {
+		ts = p.collectTS
+	}
+
+	module, ol := emitCommonPrep("", p.fnameBase, startOffset, startLine)
+
+	namePath := []string{bucket, p.state["section"]}
+
+	for i, name := range names {
+		p.emitVal(startOffset, startLine, ol, ts, module, "", namePath, name, vals[i])
+	}
+}
+
+// ------------------------------------------------------------
+
+// From stats_archives.json, which has ns_server's sampled stats per
+// bucket and per archive (minute, hour, day, etc), in either the
+// columnar form of the REST stats API or as a list of samples...
+//   {"default":{"minute":{"op":{"samples":{"timestamp":[1462541140000,...],
+//                                          "curr_items":[7303,...], ...}}}, ...}, ...}
+//   {"default":{"minute":[{"timestamp":1462541140000,"curr_items":7303,...}, ...]}}
+
+// processStatsArchives streams through the buckets of a
+// stats_archives.json file, emitting every sample's stats as VALS
+// with a path of the node, bucket and archive, like
+// "[ns_1@172.23.105.190 default minute]", and adding the stats
+// directly to the graph data that the web server serves, instead of
+// via the VALS of a graph emitter, so they're not counted twice.
+func processStatsArchives(p *fileProcessor, segment *inputFile, r io.Reader) error {
+	p.useSegment(segment)
+
+	br := bufio.NewReader(r)
+
+	// Skip any header lines that come before the JSON.
+	var offset, line int64
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return nil // No JSON.
+		}
+		if b[0] == '{' {
+			break
+		}
+
+		s, err := br.ReadString('\n')
+		offset += int64(len(s))
+		line++
+		if err != nil {
+			return nil
+		}
+	}
+
+	node := p.dirBase
+	if m := re_collect_node.FindStringSubmatch(p.dirBase); m != nil {
+		node = m[1]
+	}
+
+	module, ol := emitCommonPrep("", p.fnameBase, offset, line+1)
+
+	graphData := &GraphData{Data: map[string]GraphEntries{}}
+
+	// Every sampled stat value is handled by emitSample.
+	emitSample := func(bucket, archive string, tsMillis, v float64, name string) {
+		if name == "timestamp" {
+			return
+		}
+
+		ts := time.Unix(0, int64(tsMillis)*int64(time.Millisecond)).
+			UTC().Format(TSLayout)
+
+		valType, val := "INT", strconv.FormatFloat(v, 'f', -1, 64)
+		if v != float64(int64(v)) {
+			valType = "FLOAT"
+		}
+
+		p.dict.AddDictEntry(valType, name, val)
+		p.run.emitEntryPartGraphed(true, ts, module, "", p.dirBase,
+			p.fname, p.fnameBase, p.fnameOut, ol, offset, line+1,
+			"VALS", []string{node, bucket, archive}, name, valType, val, false)
+
+		graphData.Data[name] = append(graphData.Data[name], &GraphEntry{
+			Ts:         ts,
+			DirFName:   p.dirBase + "/" + p.fname,
+			OffsetByte: offset,
+			OffsetLine: line + 1,
+			Module:     module,
+			Path:       bucket + " " + archive,
+			Val:        val,
+			Node:       node,
+		})
+	}
+
+	dec := json.NewDecoder(br)
+
+	tok, err := dec.Token()
+	if err != nil || tok != json.Delim('{') {
+		return err
+	}
+
+	// Decode one bucket at a time, to bound memory usage.
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		bucket, _ := tok.(string)
+
+		var archives map[string]json.RawMessage
+		err = dec.Decode(&archives)
+		if err != nil {
+			return err
+		}
+
+		for _, archive := range sortedKeys(archives) {
+			raw := archives[archive]
+
+			var columnar struct {
+				Op struct {
+					Samples map[string][]float64 `json:"samples"`
+				} `json:"op"`
+			}
+
+			var samples []map[string]float64
+
+			if json.Unmarshal(raw, &columnar) == nil && columnar.Op.Samples != nil {
+				tsMillis := columnar.Op.Samples["timestamp"]
+				for _, name := range sortedKeys(columnar.Op.Samples) {
+					for i, v := range columnar.Op.Samples[name] {
+						if i < len(tsMillis) {
+							emitSample(bucket, archive, tsMillis[i], v, name)
+						}
+					}
+				}
+			} else if json.Unmarshal(raw, &samples) == nil {
+				for _, sample := range samples {
+					for _, name := range sortedKeys(sample) {
+						emitSample(bucket, archive, sample["timestamp"], sample[name], name)
+					}
+				}
+			}
+		}
+	}
+
+	p.run.m.Lock()
+	p.run.graphData.Add(graphData)
+	p.run.graphData.Rev++
+	p.run.m.Unlock()
+
+	return nil
+}
+
+// sortedKeys returns the keys of a map from JSON in sorted order.
+func sortedKeys(m interface{}) []string {
+	var rv []string
+	for _, k := range reflect.ValueOf(m).MapKeys() {
+		rv = append(rv, k.String())
+	}
+	sort.Strings(rv)
+	return rv
+}
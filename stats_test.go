@@ -0,0 +1,126 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProcessStatsEntry(t *testing.T) {
+	stars := strings.Repeat("*", 78)
+
+	content := strings.Join([]string{
+		sep, "memcached stats all", "[cbstats, all]", sep,
+		stars, "default", "",
+		" accepting_conns:    1",
+		" curr_items:     7303",
+		" ep_resident_items_rate:     99.5",
+		" ep_version:     4.5.0-2601-enterprise",
+		" time:    1462541199",
+		stars, "beer-sample", "",
+		" curr_items:     12",
+		sep, "memcached stats checkpoint", "[cbstats]", sep,
+		stars, "default", "",
+		" vb_0:num_items:   4",
+		" vb_0:state:   active",
+	}, "\n") + "\n"
+
+	p, buf := testFileProcessor("stats.log", FileMetas["stats.log"], content,
+		"VALS", "INT,FLOAT,STRING")
+	p.collectTS = "2016-05-06T06:26:39.000Z"
+
+	if err := p.process(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		fields := strings.Fields(line)
+		got = append(got, fields[0]+" "+strings.Join(fields[4:], " "))
+	}
+
+	// The default bucket's stats have the time of its "time" stat, and
+	// the beer-sample bucket's stats have the collection's time.
+	exp := []string{
+		"2016-05-06T13:26:39.000Z [default all] accepting_conns = INT 1",
+		"2016-05-06T13:26:39.000Z [default all] curr_items = INT 7303",
+		"2016-05-06T13:26:39.000Z [default all] ep_resident_items_rate = FLOAT 99.5",
+		`2016-05-06T13:26:39.000Z [default all] ep_version = STRING "4.5.0-2601-enterprise"`,
+		"2016-05-06T13:26:39.000Z [default all] time = INT 1462541199",
+		"2016-05-06T06:26:39.000Z [beer-sample all] curr_items = INT 12",
+		"2016-05-06T13:26:39.000Z [default checkpoint] vb_0:num_items = INT 4",
+		`2016-05-06T13:26:39.000Z [default checkpoint] vb_0:state = STRING "active"`,
+	}
+
+	if !reflect.DeepEqual(got, exp) {
+		t.Errorf("got: %q, expected: %q", got, exp)
+	}
+}
+
+func TestProcessStatsArchives(t *testing.T) {
+	content := "==============\nstats_archives\n==============\n" +
+		`{"default": {"minute": {"op": {"samples": {"timestamp": [1462541140000, 1462541141000],` +
+		` "curr_items": [7303, 7310], "ep_resident_items_rate": [99.5, 100]}}}},` +
+		` "beer": {"hour": [{"timestamp": 1462540000000, "cmd_get": 5},` +
+		` {"timestamp": 1462540004000, "cmd_get": 9}]}}` + "\n"
+
+	p, buf := testFileProcessor("stats_archives.json", FileMetas["stats_archives.json"],
+		content, "VALS", "INT,FLOAT")
+
+	dirBase := "cbcollect_info_ns_1@10.0.0.1_20160506-062639"
+	p.dirBase = dirBase
+	p.run.fileProgress[dirBase] = map[string]int64{}
+
+	var graphBuf bytes.Buffer
+	p.run.addGraphEmitter(&graphBuf)
+
+	if err := p.process(); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{
+		"[ns_1@10.0.0.1 default minute] curr_items = INT 7303",
+		"[ns_1@10.0.0.1 default minute] curr_items = INT 7310",
+		"[ns_1@10.0.0.1 default minute] ep_resident_items_rate = FLOAT 99.5",
+		"[ns_1@10.0.0.1 default minute] ep_resident_items_rate = INT 100",
+		"[ns_1@10.0.0.1 beer hour] cmd_get = INT 5",
+		"[ns_1@10.0.0.1 beer hour] cmd_get = INT 9",
+	}
+
+	if got := emittedVals(buf); !reflect.DeepEqual(got, exp) {
+		t.Errorf("got: %q, expected: %q", got, exp)
+	}
+
+	// The samples are graphed directly, not also via a graph emitter.
+	if graphBuf.Len() > 0 {
+		t.Errorf("expected no VALS for the graph emitter, got: %s", graphBuf.String())
+	}
+
+	counts := map[string]int{}
+	for name, entries := range p.run.graphData.Data {
+		counts[name] = len(entries)
+	}
+
+	expCounts := map[string]int{"curr_items": 2, "ep_resident_items_rate": 2, "cmd_get": 2}
+	if !reflect.DeepEqual(counts, expCounts) {
+		t.Errorf("got graph counts: %v, expected: %v", counts, expCounts)
+	}
+
+	e := p.run.graphData.Data["cmd_get"][1]
+	if e.Ts != "2016-05-06T13:06:44.000Z" || e.Path != "beer hour" ||
+		e.Node != "ns_1@10.0.0.1" || e.Val != "9" || e.OffsetLine != 4 {
+		t.Errorf("got graph entry: %+v", e)
+	}
+}
@@ -0,0 +1,138 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tsInfer infers the missing year and time zone of timestamps, like
+// syslog's "Apr 14 16:10:05", from the collection time of a
+// cbcollect-info directory, which is in the node's local time.
+type tsInfer struct {
+	collectTime time.Time // In the node's time zone.
+	zone        string    // The node's time zone offset, like "-07:00", or "Z".
+}
+
+// newTSInfer returns a tsInfer for a file, where the collection time
+// is from the cbcollect-info directory name, else from the newest
+// modTime of the file.  The zone is the node's time zone offset, if
+// known, like "-07:00".
+func newTSInfer(collectName string, modTime time.Time, zone string) *tsInfer {
+	if m := re_collect_ts.FindStringSubmatch(collectName); m != nil {
+		if zone == "" {
+			zone = "Z"
+		}
+
+		loc := time.UTC
+		if t, err := time.Parse("Z07:00", zone); err == nil {
+			loc = t.Location()
+		}
+
+		n := func(s string) int { i, _ := strconv.Atoi(s); return i }
+
+		return &tsInfer{
+			collectTime: time.Date(n(m[1]), time.Month(n(m[2])), n(m[3]),
+				n(m[4]), n(m[5]), n(m[6]), 0, loc),
+			zone: zone,
+		}
+	}
+
+	if zone == "" { // Likely the logs of this machine, like when following.
+		zone = modTime.Format("Z07:00")
+	}
+
+	return &tsInfer{collectTime: modTime, zone: zone}
+}
+
+// collectTS returns the collection time as a timestamp, for entries
+// without a timestamp.
+func (ti *tsInfer) collectTS() string {
+	return ti.collectTime.Format(TSLayout)
+}
+
+// year returns the year of an entry that only has a month and day,
+// where an entry that would be after the collection time must be from
+// the year before, like a December entry in a January collection.
+func (ti *tsInfer) year(month, day string) string {
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+
+	y := ti.collectTime.Year()
+
+	// A day of slack allows for clock skew between the logs.
+	if time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC).After(
+		time.Date(y, ti.collectTime.Month(), ti.collectTime.Day()+1, 0, 0, 0, 0, time.UTC)) {
+		y--
+	}
+
+	return strconv.Itoa(y)
+}
+
+// ------------------------------------------------------------
+
+// From a timestamp with a time zone offset...
+//   2016-04-14T16:10:09.463447-07:00 WARNING Restarting file logging
+//   [ns_server:info,2021-03-04T10:20:30.123Z,ns_1@cb.local:<0.123.0>:ns_log:init:42]
+
+var re_zone_sniff = regexp.MustCompile(`\d\d:\d\d:\d\d\.\d+(Z|[-+]\d\d:?\d\d)[\s,\]]`)
+
+// zoneSniffLines is the number of lines of a file that are checked
+// for a timestamp with a time zone offset.
+var zoneSniffLines = 20
+
+// sniffZone returns the node's time zone offset, like "-07:00", from
+// the first timestamp with an offset in the first lines of the log
+// files of a directory, else "".
+func (d *inputDir) sniffZone() string {
+	for _, file := range d.files {
+		if strings.Contains(file.name, ".gz") { // Avoid decompressing.
+			continue
+		}
+
+		if fmeta, exists := d.fileMetaFor(file.name); !exists || fmeta.Skip {
+			continue
+		}
+
+		f, err := file.open()
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(nil, ScannerBufferCapacity)
+
+		for i := 0; i < zoneSniffLines && scanner.Scan(); i++ {
+			if m := re_zone_sniff.FindStringSubmatch(scanner.Text()); m != nil {
+				f.Close()
+				return normalizeTZ(m[1])
+			}
+		}
+
+		f.Close()
+	}
+
+	return ""
+}
+
+// normalizeTZ returns a time zone offset like "-07:00" from "-0700".
+func normalizeTZ(tz string) string {
+	if len(tz) == 5 {
+		return tz[0:3] + ":" + tz[3:]
+	}
+
+	return tz
+}
@@ -0,0 +1,135 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTSInferYear(t *testing.T) {
+	tests := []struct {
+		collectName  string
+		month, day   string
+		expYear      string
+		expCollectTS string
+	}{
+		// A January collection, where December is from the year before.
+		{"cbcollect_info_ns_1@10.0.0.1_20160102-030405", "12", "31", "2015",
+			"2016-01-02T03:04:05.000-07:00"},
+		{"cbcollect_info_ns_1@10.0.0.1_20160102-030405", "01", "01", "2016", ""},
+		{"cbcollect_info_ns_1@10.0.0.1_20160102-030405", "01", "02", "2016", ""},
+		// A day of slack for clock skew.
+		{"cbcollect_info_ns_1@10.0.0.1_20160102-030405", "01", "03", "2016", ""},
+		{"cbcollect_info_ns_1@10.0.0.1_20160102-030405", "01", "04", "2015", ""},
+		// A December collection.
+		{"cbcollect_info_ns_1@10.0.0.1_20161231-235959", "12", "31", "2016", ""},
+		{"cbcollect_info_ns_1@10.0.0.1_20161231-235959", "01", "01", "2016", ""},
+	}
+
+	for i, test := range tests {
+		ti := newTSInfer(test.collectName, time.Time{}, "-07:00")
+
+		if year := ti.year(test.month, test.day); year != test.expYear {
+			t.Errorf("test %d, got year: %s, expected: %s", i, year, test.expYear)
+		}
+
+		if test.expCollectTS != "" && ti.collectTS() != test.expCollectTS {
+			t.Errorf("test %d, got collectTS: %s, expected: %s",
+				i, ti.collectTS(), test.expCollectTS)
+		}
+	}
+}
+
+func TestSyslogYearRollover(t *testing.T) {
+	content := strings.Join([]string{
+		"Dec 31 23:59:58 cb1 kernel: before midnight",
+		"Jan  1 00:00:01 cb1 kernel: after midnight",
+		"Jan  2 03:04:00 cb1 systemd[1]: before the collection",
+	}, "\n") + "\n"
+
+	p, buf := testFileProcessor("syslog.tar.gz!messages", FileMetaSyslog, content, "FULL", "")
+	p.tsInfer = newTSInfer("cbcollect_info_ns_1@10.0.0.1_20160102-030405", time.Time{}, "-07:00")
+	p.run.TSFormat = "local"
+
+	if err := p.process(); err != nil {
+		t.Fatal(err)
+	}
+
+	var tss []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		tss = append(tss, strings.Fields(line)[0])
+	}
+
+	exp := []string{
+		"2015-12-31T23:59:58.000-07:00",
+		"2016-01-01T00:00:01.000-07:00",
+		"2016-01-02T03:04:00.000-07:00",
+	}
+
+	if !reflect.DeepEqual(tss, exp) {
+		t.Errorf("got: %q, expected: %q", tss, exp)
+	}
+}
+
+func TestSniffZone(t *testing.T) {
+	tests := []struct {
+		files   map[string]string
+		expZone string
+	}{
+		{map[string]string{
+			"ns_server.info.log": "[ns_server:info,2016-04-14T16:10:05.262-07:00,ns_1@127.0.0.1:<0.1.0>:ns_log:init:42]x\n",
+		}, "-07:00"},
+		{map[string]string{
+			"memcached.log": "2016-04-14T16:10:09.463447-0700 WARNING x\n",
+		}, "-07:00"},
+		{map[string]string{
+			"ns_server.info.log": "[ns_server:info,2016-04-14T23:10:05.262Z,ns_1@127.0.0.1:<0.1.0>:ns_log:init:42]x\n",
+		}, "Z"},
+		// Files without FileMetas and syslog's timestamps don't count.
+		{map[string]string{
+			"unknown.txt":            "2016-04-14T16:10:09.463447-07:00 WARNING x\n",
+			"syslog.tar.gz!messages": "Dec 31 23:59:58 cb1 kernel: x\n",
+		}, ""},
+	}
+
+	for i, test := range tests {
+		tmp, err := ioutil.TempDir("", "mortimint_test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d := &inputDir{dir: tmp, dirBase: filepath.Base(tmp)}
+
+		for name, content := range test.files {
+			fpath := filepath.Join(tmp, strings.Replace(name, "!", "_", -1))
+			ioutil.WriteFile(fpath, []byte(content), 0600)
+
+			fi, _ := os.Stat(fpath)
+
+			file := fileInfoToInputFile(tmp, fi)
+			file.name = name
+			d.files = append(d.files, file)
+		}
+
+		if zone := d.sniffZone(); zone != test.expZone {
+			t.Errorf("test %d, got zone: %q, expected: %q", i, zone, test.expZone)
+		}
+
+		os.RemoveAll(tmp)
+	}
+}
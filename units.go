@@ -0,0 +1,83 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// From log entries with unit-suffixed quantities...
+//   2016-04-14T17:43:52.164-07:00 [INFO] moss_herder: persist took 1.5s, 12ms
+//   2016-04-14T16:10:09.463447-07:00 NOTICE bucket quota 512MB, resident 93%
+//   2016-04-14T16:10:09.463447-07:00 INFO rebalance took 1h2m3.5s
+
+// Go style durations, including compound durations like "1h2m3.5s".
+var re_duration = regexp.MustCompile(`^-?(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+\b`)
+
+var re_bytes = regexp.MustCompile(`^-?(\d+(\.\d+)?)(B| ?[kKMGTP]i?B)\b`)
+
+var re_percent = regexp.MustCompile(`^-?(\d+(\.\d+)?)%`)
+
+// byteUnits are the multipliers of byte units, where KB, MB, etc, are
+// powers of 1024, as that's what Couchbase means by them.
+var byteUnits = map[string]float64{
+	"B":  1,
+	"kB": 1 << 10, "KB": 1 << 10, "KiB": 1 << 10, "kiB": 1 << 10,
+	"MB": 1 << 20, "MiB": 1 << 20,
+	"GB": 1 << 30, "GiB": 1 << 30,
+	"TB": 1 << 40, "TiB": 1 << 40,
+	"PB": 1 << 50, "PiB": 1 << 50,
+}
+
+// unitVal parses a unit-suffixed quantity at the start of s, like
+// "1.5s", "512MB" or "93%", returning its value type (DURATION, BYTES
+// or PERCENT), its value in base units (nanoseconds, bytes or a
+// ratio), and the length of the quantity, else a length of 0.
+func unitVal(s []byte) (string, string, int) {
+	if m := re_duration.Find(s); m != nil {
+		d, err := time.ParseDuration(string(m))
+		if err == nil {
+			return "DURATION", strconv.FormatInt(int64(d), 10), len(m)
+		}
+	}
+
+	if m := re_bytes.FindSubmatch(s); m != nil {
+		f, err := strconv.ParseFloat(string(m[1]), 64)
+		if err == nil {
+			if s[0] == '-' {
+				f = -f
+			}
+
+			unit := string(m[3])
+			if unit[0] == ' ' {
+				unit = unit[1:]
+			}
+
+			return "BYTES", strconv.FormatInt(int64(f*byteUnits[unit]), 10), len(m[0])
+		}
+	}
+
+	if m := re_percent.FindSubmatch(s); m != nil {
+		f, err := strconv.ParseFloat(string(m[1]), 64)
+		if err == nil {
+			if s[0] == '-' {
+				f = -f
+			}
+
+			return "PERCENT", strconv.FormatFloat(f/100, 'f', -1, 64), len(m[0])
+		}
+	}
+
+	return "", "", 0
+}
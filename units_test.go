@@ -0,0 +1,51 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestUnitVal(t *testing.T) {
+	tests := []struct {
+		s       string
+		expType string
+		expVal  string
+		expLen  int
+	}{
+		{"1.5s", "DURATION", "1500000000", 4},
+		{"12ms, rest", "DURATION", "12000000", 4},
+		{"1h2m3.5s", "DURATION", "3723500000000", 8},
+		{"-3µs", "DURATION", "-3000", len("-3µs")},
+		{"512MB", "BYTES", "536870912", 5},
+		{"1.5 GiB free", "BYTES", "1610612736", 7},
+		{"100B", "BYTES", "100", 4},
+		{"2kB", "BYTES", "2048", 3},
+		{"93%", "PERCENT", "0.93", 3},
+		{"100%,", "PERCENT", "1", 4},
+		{"0%", "PERCENT", "0", 2},
+		{"-2.5%", "PERCENT", "-0.025", 5},
+		{"12", "", "", 0},
+		{"12sec", "", "", 0},
+		{"5MBps", "", "", 0},
+		{"s12", "", "", 0},
+		{"", "", "", 0},
+	}
+
+	for i, test := range tests {
+		valType, val, n := unitVal([]byte(test.s))
+		if valType != test.expType || val != test.expVal || n != test.expLen {
+			t.Errorf("test %d, %q, got: %s %s %d, expected: %s %s %d",
+				i, test.s, valType, val, n, test.expType, test.expVal, test.expLen)
+		}
+	}
+}
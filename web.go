@@ -10,7 +10,6 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -34,8 +33,6 @@ func (run *Run) webServer() {
 }
 
 func (run *Run) webRouter() *mux.Router {
-	graphData := GraphData{Data: map[string]GraphEntries{}}
-
 	r := mux.NewRouter()
 
 	r.HandleFunc("/progress",
@@ -44,6 +41,7 @@ func (run *Run) webRouter() *mux.Router {
 			json.NewEncoder(w).Encode(struct {
 				MinTS, MaxTS string
 				EmitDone     bool
+				Follow       bool
 				EmitProgress int64
 				FileSizes    map[string]map[string]int64
 				FileProgress map[string]map[string]int64
@@ -51,6 +49,7 @@ func (run *Run) webRouter() *mux.Router {
 				run.minTS,
 				run.maxTS,
 				run.emitDone,
+				run.run["follow"],
 				run.emitProgress,
 				run.fileSizes,
 				run.fileProgress,
@@ -61,7 +60,7 @@ func (run *Run) webRouter() *mux.Router {
 	r.HandleFunc("/graphData",
 		func(w http.ResponseWriter, r *http.Request) {
 			run.m.Lock()
-			json.NewEncoder(w).Encode(graphData)
+			json.NewEncoder(w).Encode(run.graphData)
 			run.m.Unlock()
 		}).Methods("GET")
 
@@ -81,11 +80,11 @@ func (run *Run) webRouter() *mux.Router {
 			}
 
 			run.m.Lock()
-			graphData.Add(&graphDataIn)
-			if graphData.Rev < graphDataIn.Rev {
-				graphData.Rev = graphDataIn.Rev
+			run.graphData.Add(&graphDataIn)
+			if run.graphData.Rev < graphDataIn.Rev {
+				run.graphData.Rev = graphDataIn.Rev
 			}
-			graphData.Rev++
+			run.graphData.Rev++
 			run.m.Unlock()
 		}).Methods("POST")
 
@@ -93,38 +92,42 @@ func (run *Run) webRouter() *mux.Router {
 		Handler(http.StripPrefix("/outDir/",
 			http.FileServer(http.Dir(run.OutDir)))).Methods("GET")
 
-	r.HandleFunc("/logShow/{dirName}/{fileName}/{offsetByte}",
+	r.HandleFunc("/logShow/{dirName}/{fileName:.+}/{offsetByte}",
 		func(w http.ResponseWriter, r *http.Request) {
 			vars := mux.Vars(r)
 			dirName := vars["dirName"]
 			fileName := vars["fileName"]
 
+			// The fileName may have slashes for archive members, like
+			// "syslog.tar.gz!/var/log/messages", which is safe as
+			// fileName is only matched against the listed files.
 			if strings.Index(dirName, "..") >= 0 ||
-				strings.Index(fileName, "..") >= 0 ||
-				strings.Index(dirName, string(os.PathSeparator)) >= 0 ||
-				strings.Index(fileName, string(os.PathSeparator)) >= 0 {
+				strings.Index(dirName, string(os.PathSeparator)) >= 0 {
 				http.Error(w, "error: dir/file name", 400)
 				return
 			}
 
-			dirFound := ""
-			for _, dir := range run.Dirs {
-				if dirName == path.Base(dir) {
-					dirFound = dir
-					break
+			var fileFound *inputFile
+			for _, d := range run.inputDirs {
+				if dirName == d.dirBase {
+					for _, file := range d.files {
+						if fileName == file.name {
+							fileFound = file
+						}
+					}
 				}
 			}
-			if dirFound == "" {
+			if fileFound == nil {
 				http.Error(w, "error: no match with run.Dirs", 400)
 				return
 			}
 
-			f, err := os.Open(path.Join(dirFound, fileName))
+			f, err := fileFound.open()
 			if err != nil {
 				http.Error(w, err.Error(), 400)
 				return
 			}
-			defer f.Close()
+			defer func() { f.Close() }()
 
 			offsetByte, err := strconv.ParseInt(vars["offsetByte"], 10, 64)
 			if err != nil || offsetByte < 0 {
@@ -137,13 +140,29 @@ func (run *Run) webRouter() *mux.Router {
 				&FilePart{offsetByte, 50000, ""},
 			}
 
+			var curr int64 // Current read position in f.
+
 			for _, filePart := range fileParts {
 				if filePart.Offset < 0 {
 					filePart.Length += filePart.Offset
 					filePart.Offset = 0
 				}
 
-				_, err := f.Seek(filePart.Offset, 0)
+				// A reader that can't seek, like an archive member,
+				// is reopened to go back.
+				if _, ok := f.(io.Seeker); !ok && filePart.Offset < curr {
+					f.Close()
+
+					f, err = fileFound.open()
+					if err != nil {
+						http.Error(w, err.Error(), 400)
+						return
+					}
+
+					curr = 0
+				}
+
+				err := skipTo(f, curr, filePart.Offset)
 				if err != nil {
 					http.Error(w, err.Error(), 400)
 					return
@@ -151,14 +170,19 @@ func (run *Run) webRouter() *mux.Router {
 
 				buf := make([]byte, filePart.Length)
 
-				length, err := f.Read(buf)
-				if err != nil {
+				length, err := io.ReadFull(f, buf)
+				if err != nil && err != io.ErrUnexpectedEOF {
 					http.Error(w, err.Error(), 400)
 					return
 				}
 
+				curr = filePart.Offset + int64(length)
+
 				filePart.Length = int64(length)
-				filePart.Content = string(buf)
+				filePart.Content = string(buf[0:length])
+				if run.redactor != nil {
+					filePart.Content = run.redactor.redact(filePart.Content)
+				}
 			}
 
 			run.m.Lock()
@@ -205,50 +229,12 @@ func (run *Run) webGraph(r io.Reader) {
 	scanner.Buffer(nil, ScannerBufferCapacity)
 
 	for scanner.Scan() {
-		// Example lineStr...
-		//
-		//   2016-05-05T22:59:03.076 INFO \
-		//   cbcollect_info_ns_1@172.23.105.190_20160506-062639/ns_server.fts.log \
-		//   15122577:295 fts [managerStats "manager"] TotJanitorKickErr = INT 1
-		//
-		lineStr := scanner.Text()
-		if !strings.HasPrefix(lineStr, "  ") {
-			continue
-		}
-
-		lineParts := strings.Split(spaces_re.ReplaceAllString(lineStr[2:], " "), " ")
-		if len(lineParts) < 8 ||
-			lineParts[len(lineParts)-2] != "INT" ||
-			lineParts[len(lineParts)-3] != "=" {
+		name, graphEntry := parseGraphLine(scanner.Text())
+		if graphEntry == nil {
 			continue
 		}
 
-		ts, level, dirFName, offsetByteLine, module :=
-			lineParts[0], lineParts[1], lineParts[2], lineParts[3], lineParts[4]
-
-		var offsetByte, offsetLine int64
-
-		offsetByteLineParts := strings.Split(offsetByteLine, ":")
-		if len(offsetByteLineParts) >= 2 {
-			offsetByte, _ = strconv.ParseInt(offsetByteLineParts[0], 10, 64)
-			offsetLine, _ = strconv.ParseInt(offsetByteLineParts[1], 10, 64)
-		}
-
-		path := strings.Join(lineParts[5:len(lineParts)-4], " ")
-		path = path[1 : len(path)-1]
-		name := lineParts[len(lineParts)-4]
-		val := lineParts[len(lineParts)-1]
-
-		graphData.Data[name] = append(graphData.Data[name], &GraphEntry{
-			Ts:         ts,
-			Level:      level,
-			DirFName:   dirFName,
-			OffsetByte: offsetByte,
-			OffsetLine: offsetLine,
-			Module:     module,
-			Path:       path,
-			Val:        val,
-		})
+		graphData.Data[name] = append(graphData.Data[name], graphEntry)
 
 		lines++
 	}
@@ -272,3 +258,67 @@ func (run *Run) webGraph(r io.Reader) {
 
 	fmt.Println(resp.Status)
 }
+
+// graphValTypes are the VALS value types that can be graphed.
+var graphValTypes = map[string]bool{"INT": true, "FLOAT": true, "BOOL": true,
+	"DURATION": true, "BYTES": true, "PERCENT": true}
+
+// graphValTypesCSV returns the graphValTypes as the comma-separated
+// types of an emitter, like for vals.log.
+func graphValTypesCSV() string {
+	return strings.Join(sortedKeys(graphValTypes), ",")
+}
+
+// parseGraphLine parses an emitted VALS line of a graphValType
+// into a GraphEntry, where a BOOL is graphed as 1 or 0, or returns a
+// nil GraphEntry for other kinds of lines.
+func parseGraphLine(lineStr string) (string, *GraphEntry) {
+	// Example lineStr...
+	//
+	//   2016-05-05T22:59:03.076 INFO \
+	//   cbcollect_info_ns_1@172.23.105.190_20160506-062639/ns_server.fts.log \
+	//   15122577:295 fts [managerStats "manager"] TotJanitorKickErr = INT 1
+	//
+	if !strings.HasPrefix(lineStr, "  ") {
+		return "", nil
+	}
+
+	lineParts := strings.Split(spaces_re.ReplaceAllString(lineStr[2:], " "), " ")
+	if len(lineParts) < 8 ||
+		!graphValTypes[lineParts[len(lineParts)-2]] ||
+		lineParts[len(lineParts)-3] != "=" {
+		return "", nil
+	}
+
+	ts, level, dirFName, offsetByteLine, module :=
+		lineParts[0], lineParts[1], lineParts[2], lineParts[3], lineParts[4]
+
+	var offsetByte, offsetLine int64
+
+	offsetByteLineParts := strings.Split(offsetByteLine, ":")
+	if len(offsetByteLineParts) >= 2 {
+		offsetByte, _ = strconv.ParseInt(offsetByteLineParts[0], 10, 64)
+		offsetLine, _ = strconv.ParseInt(offsetByteLineParts[1], 10, 64)
+	}
+
+	path := strings.Join(lineParts[5:len(lineParts)-4], " ")
+	path = path[1 : len(path)-1]
+	name := lineParts[len(lineParts)-4]
+	val := lineParts[len(lineParts)-1]
+	if val == "true" {
+		val = "1"
+	} else if val == "false" {
+		val = "0"
+	}
+
+	return name, &GraphEntry{
+		Ts:         ts,
+		Level:      level,
+		DirFName:   dirFName,
+		OffsetByte: offsetByte,
+		OffsetLine: offsetLine,
+		Module:     module,
+		Path:       path,
+		Val:        val,
+	}
+}
@@ -0,0 +1,87 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// From ns_server.xdcr_trace.log, which has a JSON-ish object per
+// line, where some values might not be valid JSON...
+//   {"pid":"<0.31155.2>","type":"checkpointed","ts":1462517000.123456,
+//    "repId":"7a3f1c/default/default","vb":303,"seqno":1234,
+//    "docsChecked":120,"docsWritten":100,"loc":xdcr_vbucket_repl:handle_info:235}
+
+var re_json_pair = regexp.MustCompile(`"(\w+)"\s*:\s*("(?:[^"\\]|\\.)*"|-?\d[\d.eE+-]*|true|false)`)
+
+// xdcrRepIdNames and xdcrVBucketNames are the field names that
+// identify a replication and its vbucket, in order of preference.
+var xdcrRepIdNames = []string{"repId", "rep_id", "replicationId", "id"}
+var xdcrVBucketNames = []string{"vb", "vbucket"}
+
+// processXDCRTraceEntry processes an xdcr_trace line, which is
+// emitted as FULL, and with its fields, like the checkpoint and doc
+// counters, as VALS that have a path of the replication id and
+// vbucket, like "[7a3f1c/default/default 303]".
+func processXDCRTraceEntry(p *fileProcessor, startOffset, startLine int64, lines []string) {
+	line := strings.TrimSpace(lines[0])
+	if !strings.HasPrefix(line, "{") { // Ex: a cbcollect-info header line.
+		return
+	}
+
+	var fields map[string]interface{}
+
+	err := json.Unmarshal([]byte(line), &fields)
+	if err != nil { // Not valid JSON, so just grab its simple fields.
+		fields = map[string]interface{}{}
+
+		for _, m := range re_json_pair.FindAllStringSubmatch(line, -1) {
+			if s, err := strconv.Unquote(m[2]); err == nil {
+				fields[m[1]] = s
+			} else if f, err := strconv.ParseFloat(m[2], 64); err == nil {
+				fields[m[1]] = f
+			} else {
+				fields[m[1]] = m[2] == "true"
+			}
+		}
+	}
+
+	ts := jsonTS(fields, p.collectTS)
+
+	module, ol := emitCommonPrep("", p.fnameBase, startOffset, startLine)
+
+	p.run.emitEntryFull(ts, module, "", p.dirBase,
+		p.fname, p.fnameBase, p.fnameOut, ol, startOffset, startLine, lines)
+
+	var namePath []string
+	for _, names := range [][]string{xdcrRepIdNames, xdcrVBucketNames} {
+		for _, name := range names {
+			if val, ok := jsonValString(fields[name]); ok {
+				namePath = append(namePath, val)
+				break
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(fields) {
+		if name == "ts" || name == "pid" {
+			continue
+		}
+
+		if val, ok := jsonValString(fields[name]); ok {
+			p.emitVal(startOffset, startLine, ol, ts, module, "", namePath, name, val)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+//  Copyright (c) 2016 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License");
+//  you may not use this file except in compliance with the
+//  License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing,
+//  software distributed under the License is distributed on an "AS
+//  IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+//  express or implied. See the License for the specific language
+//  governing permissions and limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProcessXDCRTraceEntry(t *testing.T) {
+	tests := []struct {
+		line  string
+		expTS string
+		exp   []string
+	}{
+		// Valid JSON.
+		{`{"pid":"<0.31155.2>","type":"checkpointed","ts":1462517000.123456,` +
+			`"repId":"7a3f1c/default/default","vb":303,"seqno":1234,"ok":true}`,
+			"2016-05-06T06:43:20.123Z",
+			[]string{`[7a3f1c/default/default 303] ok = BOOL true`,
+				`[7a3f1c/default/default 303] repId = STRING "7a3f1c/default/default"`,
+				"[7a3f1c/default/default 303] seqno = INT 1234",
+				`[7a3f1c/default/default 303] type = STRING "checkpointed"`,
+				"[7a3f1c/default/default 303] vb = INT 303"}},
+		// Not valid JSON, due to the unquoted loc, so the simple
+		// fields are grabbed instead.
+		{`{"pid":"<0.31155.2>","type":"checkpointed","ts":1462517000.123456,` +
+			`"rep_id":"7a3f1c/default/beer","vbucket":7,"docsChecked":120,` +
+			`"stale":false,"loc":xdcr_vbucket_repl:handle_info:235}`,
+			"2016-05-06T06:43:20.123Z",
+			[]string{"[7a3f1c/default/beer 7] docsChecked = INT 120",
+				`[7a3f1c/default/beer 7] rep_id = STRING "7a3f1c/default/beer"`,
+				"[7a3f1c/default/beer 7] stale = BOOL false",
+				`[7a3f1c/default/beer 7] type = STRING "checkpointed"`,
+				"[7a3f1c/default/beer 7] vbucket = INT 7"}},
+		// Without a replication id or vbucket, or a ts.
+		{`{"type":"started","count":2}`,
+			"2016-05-06T06:26:39.000Z",
+			[]string{"[] count = INT 2", `[] type = STRING "started"`}},
+		// A repId is preferred over an id.
+		{`{"id":"x","repId":"7a3f1c/a/b","vb":1}`,
+			"2016-05-06T06:26:39.000Z",
+			[]string{`[7a3f1c/a/b 1] id = STRING "x"`,
+				`[7a3f1c/a/b 1] repId = STRING "7a3f1c/a/b"`,
+				"[7a3f1c/a/b 1] vb = INT 1"}},
+	}
+
+	for i, test := range tests {
+		content := "h1\nh2\nh3\nh4\n" + test.line + "\n"
+
+		p, buf := testFileProcessor("ns_server.xdcr_trace.log", FileMetas["ns_server.xdcr_trace.log"],
+			content, "FULL,VALS", "INT,FLOAT,BOOL,STRING")
+		p.collectTS = "2016-05-06T06:26:39.000Z"
+
+		if err := p.process(); err != nil {
+			t.Fatal(err)
+		}
+
+		var full []string
+		var vals []string
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if strings.Contains(line, " VALS ") {
+				vals = append(vals, line)
+			} else {
+				full = append(full, line)
+			}
+		}
+
+		if len(full) != 1 || strings.Fields(full[0])[0] != test.expTS ||
+			!strings.HasSuffix(full[0], test.line) {
+			t.Errorf("test %d, got FULL: %q, expected ts: %s, line: %q",
+				i, full, test.expTS, test.line)
+		}
+
+		if got := emittedVals(buf); !reflect.DeepEqual(got, test.exp) {
+			t.Errorf("test %d, got: %q, expected: %q", i, got, test.exp)
+		}
+	}
+}